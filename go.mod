@@ -1,6 +1,6 @@
 module github.com/WeiXinao/xkit
 
-go 1.20
+go 1.24
 
 require github.com/stretchr/testify v1.9.0
 
@@ -2,6 +2,8 @@ package queue
 
 import (
 	"errors"
+	"sort"
+
 	"github.com/WeiXinao/xkit"
 	"github.com/WeiXinao/xkit/internal/slice"
 )
@@ -15,6 +17,11 @@ type PriorityQueue[T any] struct {
 	compare  xkit.Comparator[T]
 	capacity int
 	data     []T
+	// stable 为 true 的时候，优先级相同的元素按照入队的顺序出队
+	// seqs 和 data 一一对应，是元素入队时的序号，seq 是下一个序号
+	stable bool
+	seqs   []uint64
+	seq    uint64
 }
 
 func NewPriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *PriorityQueue[T] {
@@ -30,6 +37,14 @@ func NewPriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *Priority
 	}
 }
 
+// NewStablePriorityQueue 创建稳定的优先队列，优先级相同的元素按照入队的顺序出队
+func NewStablePriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *PriorityQueue[T] {
+	p := NewPriorityQueue[T](capacity, compare)
+	p.stable = true
+	p.seqs = make([]uint64, 1, cap(p.data))
+	return p
+}
+
 func (p *PriorityQueue[T]) Len() int {
 	return len(p.data) - 1
 }
@@ -63,9 +78,13 @@ func (p *PriorityQueue[T]) Enqueue(t T) error {
 		return ErrOutOfCapacity
 	}
 	p.data = append(p.data, t)
+	if p.stable {
+		p.seqs = append(p.seqs, p.seq)
+		p.seq++
+	}
 	node, parent := len(p.data)-1, (len(p.data)-1)/2
-	for parent > 0 && p.compare(p.data[node], p.data[parent]) < 0 {
-		p.data[node], p.data[parent] = p.data[parent], p.data[node]
+	for parent > 0 && p.less(node, parent) {
+		p.swap(node, parent)
 		node = parent
 		parent = parent / 2
 	}
@@ -80,32 +99,100 @@ func (p *PriorityQueue[T]) Dequeue() (T, error) {
 	}
 
 	pop := p.data[1]
-	p.data[1] = p.data[len(p.data)-1]
-	p.data = p.data[:len(p.data)-1]
+	last := len(p.data) - 1
+	p.data[1] = p.data[last]
+	p.data = p.data[:last]
+	if p.stable {
+		p.seqs[1] = p.seqs[last]
+		p.seqs = p.seqs[:last]
+	}
 	p.shrinkIfNecessary()
-	p.heapify(p.data, len(p.data)-1, 1)
+	p.heapify(len(p.data)-1, 1)
 	return pop, nil
 }
 
+// Range 按照堆中的顺序遍历元素，index 是元素在堆中的下标，从 0 开始
+// 堆中的顺序只保证父节点的优先级不低于子节点
+func (p *PriorityQueue[T]) Range(fn func(index int, t T) error) error {
+	for i := 1; i < len(p.data); i++ {
+		if err := fn(i-1, p.data[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AsSlice 按照堆中的顺序返回所有元素的副本
+func (p *PriorityQueue[T]) AsSlice() []T {
+	res := make([]T, len(p.data)-1)
+	copy(res, p.data[1:])
+	return res
+}
+
+// RangeSorted 按照出队的顺序遍历元素，不会修改队列
+func (p *PriorityQueue[T]) RangeSorted(fn func(index int, t T) error) error {
+	for i, t := range p.AsSortedSlice() {
+		if err := fn(i, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AsSortedSlice 按照出队的顺序返回所有元素的副本，不会修改队列
+func (p *PriorityQueue[T]) AsSortedSlice() []T {
+	idx := make([]int, len(p.data)-1)
+	for i := range idx {
+		idx[i] = i + 1
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return p.less(idx[i], idx[j])
+	})
+	res := make([]T, len(idx))
+	for i, j := range idx {
+		res[i] = p.data[j]
+	}
+	return res
+}
+
 func (p *PriorityQueue[T]) shrinkIfNecessary() {
 	if p.IsBoundless() {
 		p.data = slice.Shrink[T](p.data)
+		if p.stable {
+			p.seqs = slice.Shrink[uint64](p.seqs)
+		}
 	}
 }
 
-func (p *PriorityQueue[T]) heapify(data []T, n, i int) {
+func (p *PriorityQueue[T]) heapify(n, i int) {
 	minPos := i
 	for {
-		if left := i * 2; left <= n && p.compare(data[left], data[minPos]) < 0 {
+		if left := i * 2; left <= n && p.less(left, minPos) {
 			minPos = left
 		}
-		if right := i*2 + 1; right <= n && p.compare(data[right], data[minPos]) < 0 {
+		if right := i*2 + 1; right <= n && p.less(right, minPos) {
 			minPos = right
 		}
 		if minPos == i {
 			break
 		}
-		data[i], data[minPos] = data[minPos], data[i]
+		p.swap(i, minPos)
 		i = minPos
 	}
 }
+
+// less 判断下标 i 的元素是否比下标 j 的元素先出队
+func (p *PriorityQueue[T]) less(i, j int) bool {
+	c := p.compare(p.data[i], p.data[j])
+	if c == 0 && p.stable {
+		return p.seqs[i] < p.seqs[j]
+	}
+	return c < 0
+}
+
+func (p *PriorityQueue[T]) swap(i, j int) {
+	p.data[i], p.data[j] = p.data[j], p.data[i]
+	if p.stable {
+		p.seqs[i], p.seqs[j] = p.seqs[j], p.seqs[i]
+	}
+}
@@ -429,6 +429,85 @@ func TestPriorityQueue_Shrink(t *testing.T) {
 	}
 }
 
+type stableItem struct {
+	priority int
+	name     string
+}
+
+func compareStableItem(src, dst stableItem) int {
+	return compare()(src.priority, dst.priority)
+}
+
+func TestPriorityQueue_Stable(t *testing.T) {
+	items := []stableItem{
+		{priority: 2, name: "a"}, {priority: 1, name: "b"}, {priority: 2, name: "c"},
+		{priority: 1, name: "d"}, {priority: 2, name: "e"}, {priority: 1, name: "f"},
+		{priority: 2, name: "g"}, {priority: 2, name: "h"}, {priority: 1, name: "i"},
+	}
+	testCases := []struct {
+		name     string
+		capacity int
+	}{
+		{name: "无界", capacity: 0},
+		{name: "有界", capacity: len(items)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewStablePriorityQueue[stableItem](tc.capacity, compareStableItem)
+			for _, item := range items {
+				require.NoError(t, q.Enqueue(item))
+			}
+			// 出队到一半再入队，新的元素排在同优先级的旧元素后面
+			for _, want := range []string{"b", "d", "f"} {
+				item, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, want, item.name)
+			}
+			require.NoError(t, q.Enqueue(stableItem{priority: 1, name: "j"}))
+			res := make([]string, 0, q.Len())
+			for q.Len() > 0 {
+				item, err := q.Dequeue()
+				require.NoError(t, err)
+				res = append(res, item.name)
+			}
+			assert.Equal(t, []string{"i", "j", "a", "c", "e", "g", "h"}, res)
+		})
+	}
+}
+
+func TestPriorityQueue_Range(t *testing.T) {
+	q := priorityQueueOf(0, []int{6, 5, 4, 3, 2, 1}, compare())
+	assert.Equal(t, q.data[1:], q.AsSlice())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, q.AsSortedSlice())
+
+	var heapOrder []int
+	require.NoError(t, q.Range(func(index int, t int) error {
+		heapOrder = append(heapOrder, t)
+		return nil
+	}))
+	assert.Equal(t, q.AsSlice(), heapOrder)
+
+	var sorted []int
+	err := q.RangeSorted(func(index int, t int) error {
+		if index == 3 {
+			return ErrEmptyQueue
+		}
+		sorted = append(sorted, t)
+		return nil
+	})
+	assert.Equal(t, ErrEmptyQueue, err)
+	assert.Equal(t, []int{1, 2, 3}, sorted)
+	// 遍历不会修改队列
+	assert.Equal(t, 6, q.Len())
+
+	stable := NewStablePriorityQueue[stableItem](0, compareStableItem)
+	for _, item := range []stableItem{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}} {
+		require.NoError(t, stable.Enqueue(item))
+	}
+	assert.Equal(t, []stableItem{{1, "b"}, {1, "d"}, {2, "a"}, {2, "c"}}, stable.AsSortedSlice())
+	assert.Equal(t, []stableItem{}, NewPriorityQueue[stableItem](0, compareStableItem).AsSlice())
+}
+
 func priorityQueueOf(capacity int, data []int, compare xkit.Comparator[int]) *PriorityQueue[int] {
 	q := NewPriorityQueue[int](capacity, compare)
 	for _, el := range data {
@@ -3,6 +3,7 @@ package tree
 import (
 	"errors"
 	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/internal/errs"
 )
 
 type color bool
@@ -22,6 +23,8 @@ type rbNode[K any, V any] struct {
 	key                 K
 	value               V
 	left, right, parent *rbNode[K, V]
+	// size 以该节点为根的子树的节点数量，用于 Rank 和 Select
+	size int
 }
 
 func (node *rbNode[K, V]) setNode(v V) {
@@ -31,6 +34,15 @@ func (node *rbNode[K, V]) setNode(v V) {
 	node.value = v
 }
 
+func (node *rbNode[K, V]) keyValue() (K, V, error) {
+	if node == nil {
+		var k K
+		var v V
+		return k, v, ErrRBTreeNotRBNode
+	}
+	return node.key, node.value, nil
+}
+
 type RBTree[K any, V any] struct {
 	root    *rbNode[K, V]
 	compare xkit.Comparator[K]
@@ -45,6 +57,7 @@ func newRBNode[K any, V any](key K, value V) *rbNode[K, V] {
 		left:   nil,
 		right:  nil,
 		parent: nil,
+		size:   1,
 	}
 }
 
@@ -55,6 +68,49 @@ func NewRBTree[K any, V any](compare xkit.Comparator[K]) *RBTree[K, V] {
 	}
 }
 
+// NewRBTreeFromSorted 根据严格递增的 keys 直接构造一棵平衡的红黑树，时间复杂度是 O(n)
+// values 和 keys 一一对应，values 为 nil 的时候所有的值都是零值
+// 调用方需要保证 keys 严格递增
+func NewRBTreeFromSorted[K any, V any](compare xkit.Comparator[K], keys []K, values []V) *RBTree[K, V] {
+	rb := NewRBTree[K, V](compare)
+	// 除了最深的一层，其它层都是满的，最深一层的节点染成红色，其余的节点都是黑色，
+	// 这样每条路径上的黑色节点数量都相同
+	redLevel := 0
+	for m := len(keys) - 1; m >= 0; m = m/2 - 1 {
+		redLevel++
+	}
+	rb.root = buildFromSorted(keys, values, 0, len(keys)-1, 0, redLevel)
+	rb.size = len(keys)
+	return rb
+}
+
+// buildFromSorted 用 keys[lo:hi+1] 构造子树，level 是子树的根所在的层数
+func buildFromSorted[K any, V any](keys []K, values []V, lo, hi, level, redLevel int) *rbNode[K, V] {
+	if lo > hi {
+		return nil
+	}
+	mid := (lo + hi) / 2
+	var val V
+	if values != nil {
+		val = values[mid]
+	}
+	node := newRBNode(keys[mid], val)
+	node.color = Black
+	if level == redLevel {
+		node.color = Red
+	}
+	node.left = buildFromSorted(keys, values, lo, mid-1, level+1, redLevel)
+	node.right = buildFromSorted(keys, values, mid+1, hi, level+1, redLevel)
+	if node.left != nil {
+		node.left.parent = node
+	}
+	if node.right != nil {
+		node.right.parent = node
+	}
+	node.size = hi - lo + 1
+	return node
+}
+
 func (rb *RBTree[K, V]) Size() int {
 	if rb == nil {
 		return 0
@@ -109,6 +165,110 @@ func (rb *RBTree[K, V]) KeyValues() ([]K, []V) {
 	return keys, values
 }
 
+// Floor 查找小于等于 key 的最大节点
+func (rb *RBTree[K, V]) Floor(key K) (K, V, error) {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp == 0 {
+			return node.key, node.value, nil
+		}
+		if cmp < 0 {
+			node = node.left
+		} else {
+			res = node
+			node = node.right
+		}
+	}
+	return res.keyValue()
+}
+
+// Ceiling 查找大于等于 key 的最小节点
+func (rb *RBTree[K, V]) Ceiling(key K) (K, V, error) {
+	var res *rbNode[K, V]
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp == 0 {
+			return node.key, node.value, nil
+		}
+		if cmp > 0 {
+			node = node.right
+		} else {
+			res = node
+			node = node.left
+		}
+	}
+	return res.keyValue()
+}
+
+// Min 查找最小的节点
+func (rb *RBTree[K, V]) Min() (K, V, error) {
+	node := rb.root
+	for node.getLeft() != nil {
+		node = node.left
+	}
+	return node.keyValue()
+}
+
+// Max 查找最大的节点
+func (rb *RBTree[K, V]) Max() (K, V, error) {
+	node := rb.root
+	for node.getRight() != nil {
+		node = node.right
+	}
+	return node.keyValue()
+}
+
+// Rank 返回小于 key 的节点数量，key 本身不需要存在
+func (rb *RBTree[K, V]) Rank(key K) int {
+	rank := 0
+	node := rb.root
+	for node != nil {
+		cmp := rb.compare(key, node.key)
+		if cmp < 0 {
+			node = node.left
+		} else if cmp > 0 {
+			rank += node.left.getSize() + 1
+			node = node.right
+		} else {
+			return rank + node.left.getSize()
+		}
+	}
+	return rank
+}
+
+// Select 返回按照从小到大排序之后下标为 index 的节点
+// 下标从 0 开始，超出范围会返回错误
+func (rb *RBTree[K, V]) Select(index int) (K, V, error) {
+	if index < 0 || index >= rb.Size() {
+		var k K
+		var v V
+		return k, v, errs.NewErrIndexOutOfRange(rb.Size(), index)
+	}
+	node := rb.root
+	for {
+		leftSize := node.left.getSize()
+		if index < leftSize {
+			node = node.left
+		} else if index > leftSize {
+			index -= leftSize + 1
+			node = node.right
+		} else {
+			return node.key, node.value, nil
+		}
+	}
+}
+
+// CountRange 返回落在 [lo, hi) 中的节点数量
+func (rb *RBTree[K, V]) CountRange(lo, hi K) int {
+	if rb.compare(lo, hi) >= 0 {
+		return 0
+	}
+	return rb.Rank(hi) - rb.Rank(lo)
+}
+
 // inOrderTraversal 中序遍历
 func (rb *RBTree[K, V]) inOrderTraversal(visit func(node *rbNode[K, V])) {
 	stack := make([]*rbNode[K, V], 0, rb.size)
@@ -166,12 +326,16 @@ func (rb *RBTree[K, V]) addNode(node *rbNode[K, V]) error {
 			key:    node.key,
 			value:  node.value,
 			parent: parent,
+			size:   1,
 		}
 		if cmp < 0 {
 			parent.left = fixNode
 		} else {
 			parent.right = fixNode
 		}
+		for p := parent; p != nil; p = p.parent {
+			p.size++
+		}
 	}
 	rb.size++
 	rb.fixAfterAdd(fixNode)
@@ -198,6 +362,12 @@ func (rb *RBTree[K, V]) deleteNode(tgt *rbNode[K, V]) {
 		node.value = s.value
 		node = s
 	}
+	// 在真正摘除 node 之前，更新所有祖先的子树大小
+	// node 的 size 置为 0，这样在着色旋转的时候它不会被计入
+	for p := node.parent; p != nil; p = p.parent {
+		p.size--
+	}
+	node.size = 0
 	var replacement *rbNode[K, V]
 	//	node 节点只有一个非空子节点
 	if node.left != nil {
@@ -422,6 +592,8 @@ func (rb *RBTree[K, V]) rotateLeft(node *rbNode[K, V]) {
 	}
 	r.left = node
 	node.parent = r
+	r.size = node.size
+	node.size = node.left.getSize() + node.right.getSize() + 1
 }
 
 func (rb *RBTree[K, V]) rotateRight(node *rbNode[K, V]) {
@@ -443,6 +615,8 @@ func (rb *RBTree[K, V]) rotateRight(node *rbNode[K, V]) {
 	}
 	l.right = node
 	node.parent = l
+	l.size = node.size
+	node.size = node.left.getSize() + node.right.getSize() + 1
 }
 
 func (node *rbNode[K, V]) getColor() color {
@@ -459,6 +633,13 @@ func (node *rbNode[K, V]) setColor(color color) {
 	node.color = color
 }
 
+func (node *rbNode[K, V]) getSize() int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
 func (node *rbNode[K, V]) getParent() *rbNode[K, V] {
 	if node == nil {
 		return nil
@@ -3,6 +3,8 @@ package tree
 import (
 	"errors"
 	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/internal/errs"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -905,6 +907,7 @@ func TestNewRBNode(t *testing.T) {
 				right:  nil,
 				parent: nil,
 				color:  Red,
+				size:   1,
 			},
 		},
 	}
@@ -1422,3 +1425,194 @@ func nodeCheck[K any, V any](node *rbNode[K, V], count int, num int) bool {
 	}
 	return nodeCheck(node.left, count, num) && nodeCheck(node.right, count, num)
 }
+
+func TestRBTree_FloorCeiling(t *testing.T) {
+	rb := NewRBTree[int, int](compare())
+	for _, k := range []int{10, 20, 30, 40} {
+		assert.NoError(t, rb.Add(k, k*10))
+	}
+	tests := []struct {
+		name        string
+		key         int
+		wantFloor   int
+		floorErr    error
+		wantCeiling int
+		ceilingErr  error
+	}{
+		{name: "less than min", key: 5, floorErr: ErrRBTreeNotRBNode, wantCeiling: 10},
+		{name: "equal", key: 20, wantFloor: 20, wantCeiling: 20},
+		{name: "between", key: 25, wantFloor: 20, wantCeiling: 30},
+		{name: "greater than max", key: 45, wantFloor: 40, ceilingErr: ErrRBTreeNotRBNode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, v, err := rb.Floor(tt.key)
+			assert.Equal(t, tt.floorErr, err)
+			assert.Equal(t, tt.wantFloor, k)
+			assert.Equal(t, tt.wantFloor*10, v)
+			k, v, err = rb.Ceiling(tt.key)
+			assert.Equal(t, tt.ceilingErr, err)
+			assert.Equal(t, tt.wantCeiling, k)
+			assert.Equal(t, tt.wantCeiling*10, v)
+		})
+	}
+}
+
+func TestRBTree_MinMax(t *testing.T) {
+	rb := NewRBTree[int, int](compare())
+	_, _, err := rb.Min()
+	assert.Equal(t, ErrRBTreeNotRBNode, err)
+	_, _, err = rb.Max()
+	assert.Equal(t, ErrRBTreeNotRBNode, err)
+	for _, k := range []int{3, 1, 4, 5, 9, 2, 6} {
+		assert.NoError(t, rb.Add(k, k))
+	}
+	k, _, err := rb.Min()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, k)
+	k, _, err = rb.Max()
+	assert.NoError(t, err)
+	assert.Equal(t, 9, k)
+}
+
+func TestRBTree_RankSelect(t *testing.T) {
+	rb := NewRBTree[int, int](compare())
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		assert.NoError(t, rb.Add(k, k*10))
+	}
+	rankTests := []struct {
+		name string
+		key  int
+		want int
+	}{
+		{name: "less than min", key: 5, want: 0},
+		{name: "min", key: 10, want: 0},
+		{name: "exist", key: 30, want: 2},
+		{name: "not exist", key: 35, want: 3},
+		{name: "greater than max", key: 60, want: 5},
+	}
+	for _, tt := range rankTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rb.Rank(tt.key))
+		})
+	}
+
+	selectTests := []struct {
+		name    string
+		index   int
+		wantKey int
+		wantErr error
+	}{
+		{name: "negative", index: -1, wantErr: errs.NewErrIndexOutOfRange(5, -1)},
+		{name: "first", index: 0, wantKey: 10},
+		{name: "middle", index: 2, wantKey: 30},
+		{name: "last", index: 4, wantKey: 50},
+		{name: "out of range", index: 5, wantErr: errs.NewErrIndexOutOfRange(5, 5)},
+	}
+	for _, tt := range selectTests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, v, err := rb.Select(tt.index)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantKey, k)
+			assert.Equal(t, tt.wantKey*10, v)
+		})
+	}
+
+	assert.Equal(t, 3, rb.CountRange(15, 45))
+	assert.Equal(t, 2, rb.CountRange(10, 30))
+	assert.Equal(t, 0, rb.CountRange(30, 30))
+	assert.Equal(t, 0, rb.CountRange(40, 10))
+}
+
+// 随机增删之后，每个节点的子树大小都应该正确，Rank 和 Select 应该互逆
+func TestRBTree_SizeAugmentation(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	rb := NewRBTree[int, int](compare())
+	exist := make(map[int]bool)
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if exist[k] {
+			_, ok := rb.Delete(k)
+			assert.True(t, ok)
+			delete(exist, k)
+		} else {
+			assert.NoError(t, rb.Add(k, k))
+			exist[k] = true
+		}
+		if i%100 == 0 {
+			assert.True(t, checkSize(rb.root))
+			assert.True(t, IsRedBlackTree[int](rb.root))
+		}
+	}
+	assert.True(t, checkSize(rb.root))
+	assert.Equal(t, len(exist), rb.root.getSize())
+	for i := 0; i < rb.Size(); i++ {
+		k, _, err := rb.Select(i)
+		assert.NoError(t, err)
+		assert.Equal(t, i, rb.Rank(k))
+	}
+}
+
+func checkSize[K any, V any](node *rbNode[K, V]) bool {
+	if node == nil {
+		return true
+	}
+	if node.size != node.left.getSize()+node.right.getSize()+1 {
+		return false
+	}
+	return checkSize(node.left) && checkSize(node.right)
+}
+
+func TestNewRBTreeFromSorted(t *testing.T) {
+	for n := 0; n <= 200; n++ {
+		keys := make([]int, n)
+		values := make([]int, n)
+		for i := range keys {
+			keys[i] = i * 2
+			values[i] = i
+		}
+		rb := NewRBTreeFromSorted[int, int](compare(), keys, values)
+		assert.Equal(t, n, rb.Size())
+		assert.True(t, IsRedBlackTree[int](rb.root), "n = %d", n)
+		_, ok := blackHeight(rb.root)
+		assert.True(t, ok, "n = %d", n)
+		assert.True(t, checkSize(rb.root))
+		gotKeys, gotValues := rb.KeyValues()
+		assert.Equal(t, keys, gotKeys)
+		assert.Equal(t, values, gotValues)
+
+		// 构造出来的树可以继续正常地增删
+		assert.NoError(t, rb.Add(-1, -1))
+		assert.NoError(t, rb.Add(2*n+1, 0))
+		if n > 0 {
+			_, ok = rb.Delete(keys[n/2])
+			assert.True(t, ok)
+		}
+		assert.True(t, IsRedBlackTree[int](rb.root))
+		assert.True(t, checkSize(rb.root))
+	}
+
+	rb := NewRBTreeFromSorted[int, string](compare(), []int{1, 2, 3}, nil)
+	val, err := rb.Find(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "", val)
+}
+
+// blackHeight 返回子树到每个叶子（包括 nil）路径上的黑色节点数量，路径之间不一致的时候返回 false
+func blackHeight[K any, V any](node *rbNode[K, V]) (int, bool) {
+	if node == nil {
+		return 1, true
+	}
+	left, ok := blackHeight(node.left)
+	if !ok {
+		return 0, false
+	}
+	right, ok := blackHeight(node.right)
+	if !ok || left != right {
+		return 0, false
+	}
+	if node.getColor() == Black {
+		left++
+	}
+	return left, true
+}
@@ -19,7 +19,6 @@ func (b *builtinMap[K, V]) Put(key K, val V) error {
 
 func (b *builtinMap[K, V]) Get(k K) (V, bool) {
 	v, ok := b.data[k]
-	delete(b.data, k)
 	return v, ok
 }
 
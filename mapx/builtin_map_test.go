@@ -90,6 +90,9 @@ func TestBuiltinMap_Get(t *testing.T) {
 			val, ok := m.Get(tc.key)
 			assert.Equal(t, tc.found, ok)
 			assert.Equal(t, tc.wantVal, val)
+			// Get 不应该删除键
+			_, ok = m.data[tc.key]
+			assert.Equal(t, tc.found, ok)
 		})
 	}
 }
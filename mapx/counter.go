@@ -0,0 +1,172 @@
+package mapx
+
+import (
+	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/internal/queue"
+	"github.com/WeiXinao/xkit/tuple/pair"
+)
+
+// Counter 计数器，也就是多重集合
+// 它记录了每个键出现的次数，计数小于等于 0 的键会被移除
+type Counter[K any] struct {
+	m      mapi[K, int]
+	newMap func() mapi[K, int]
+	total  int
+}
+
+// NewCounter 创建一个基于内置 map 的 Counter
+func NewCounter[K comparable]() *Counter[K] {
+	newMap := func() mapi[K, int] {
+		return newBuiltinMap[K, int](0)
+	}
+	return &Counter[K]{
+		m:      newMap(),
+		newMap: newMap,
+	}
+}
+
+// NewTreeCounter 创建一个基于 TreeMap 的 Counter
+// Keys 和 Elements 会按照键的顺序返回
+// 注意：
+// - compare 不能为 nil
+func NewTreeCounter[K any](compare xkit.Comparator[K]) (*Counter[K], error) {
+	m, err := NewTreeMap[K, int](compare)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter[K]{
+		m: m,
+		newMap: func() mapi[K, int] {
+			res, _ := NewTreeMap[K, int](compare)
+			return res
+		},
+	}, nil
+}
+
+// Add 将 key 的计数增加 n，n 可以是负数
+// 如果计数变为小于等于 0，那么 key 会被移除
+func (c *Counter[K]) Add(key K, n int) error {
+	old, _ := c.m.Get(key)
+	return c.set(key, old+n)
+}
+
+// Count 返回 key 的计数，不存在的键计数为 0
+func (c *Counter[K]) Count(key K) int {
+	cnt, _ := c.m.Get(key)
+	return cnt
+}
+
+func (c *Counter[K]) set(key K, cnt int) error {
+	old, _ := c.m.Get(key)
+	if cnt <= 0 {
+		c.m.Delete(key)
+		c.total -= old
+		return nil
+	}
+	if err := c.m.Put(key, cnt); err != nil {
+		return err
+	}
+	c.total += cnt - old
+	return nil
+}
+
+// Total 返回所有计数之和
+func (c *Counter[K]) Total() int {
+	return c.total
+}
+
+// Len 返回不同键的数量
+func (c *Counter[K]) Len() int64 {
+	return c.m.Len()
+}
+
+// Keys 返回所有计数大于 0 的键
+func (c *Counter[K]) Keys() []K {
+	return c.m.Keys()
+}
+
+// Elements 返回所有元素，每个键按照它的计数重复出现
+func (c *Counter[K]) Elements() []K {
+	res := make([]K, 0, c.total)
+	for _, key := range c.m.Keys() {
+		for i := c.Count(key); i > 0; i-- {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// MostCommon 按照计数从大到小返回前 n 个键以及它们的计数
+// n <= 0 的时候返回所有的键
+// 计数相同的键之间的顺序是不确定的
+func (c *Counter[K]) MostCommon(n int) []pair.Pair[K, int] {
+	keys := c.m.Keys()
+	if n <= 0 || n > len(keys) {
+		n = len(keys)
+	}
+	// 用容量为 n 的小顶堆保留计数最大的 n 个键
+	pq := queue.NewPriorityQueue[pair.Pair[K, int]](n, func(src, dst pair.Pair[K, int]) int {
+		return xkit.ComparatorRealNumber[int](src.Value, dst.Value)
+	})
+	for _, key := range keys {
+		p := pair.NewPair(key, c.Count(key))
+		if pq.Len() < n {
+			_ = pq.Enqueue(p)
+			continue
+		}
+		if top, err := pq.Peek(); err == nil && top.Value < p.Value {
+			_, _ = pq.Dequeue()
+			_ = pq.Enqueue(p)
+		}
+	}
+	res := make([]pair.Pair[K, int], pq.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i], _ = pq.Dequeue()
+	}
+	return res
+}
+
+// Union 返回一个新的 Counter，每个键的计数取两者中的较大值
+func (c *Counter[K]) Union(other *Counter[K]) *Counter[K] {
+	return c.merge(other, func(src, dst int) int {
+		if src > dst {
+			return src
+		}
+		return dst
+	})
+}
+
+// Intersect 返回一个新的 Counter，每个键的计数取两者中的较小值
+func (c *Counter[K]) Intersect(other *Counter[K]) *Counter[K] {
+	return c.merge(other, func(src, dst int) int {
+		if src < dst {
+			return src
+		}
+		return dst
+	})
+}
+
+// Subtract 返回一个新的 Counter，每个键的计数为 c 的计数减去 other 的计数
+// 结果中只保留计数大于 0 的键
+func (c *Counter[K]) Subtract(other *Counter[K]) *Counter[K] {
+	return c.merge(other, func(src, dst int) int {
+		return src - dst
+	})
+}
+
+// merge 使用 c 的底层实现创建新的 Counter，并且对两者的所有键应用 fn
+func (c *Counter[K]) merge(other *Counter[K], fn func(src, dst int) int) *Counter[K] {
+	res := &Counter[K]{
+		m:      c.newMap(),
+		newMap: c.newMap,
+	}
+	for _, key := range c.m.Keys() {
+		_ = res.set(key, fn(c.Count(key), other.Count(key)))
+	}
+	for _, key := range other.m.Keys() {
+		if _, ok := c.m.Get(key); !ok {
+			_ = res.set(key, fn(0, other.Count(key)))
+		}
+	}
+	return res
+}
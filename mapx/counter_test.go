@@ -0,0 +1,154 @@
+package mapx
+
+import (
+	"testing"
+
+	"github.com/WeiXinao/xkit/tuple/pair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTreeCounter(t *testing.T, keys ...int) *Counter[int] {
+	c, err := NewTreeCounter[int](compare())
+	require.NoError(t, err)
+	for _, k := range keys {
+		require.NoError(t, c.Add(k, 1))
+	}
+	return c
+}
+
+func TestNewTreeCounter(t *testing.T) {
+	_, err := NewTreeCounter[int](nil)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+}
+
+func TestCounter_Add(t *testing.T) {
+	tests := []struct {
+		name      string
+		adds      []pair.Pair[string, int]
+		wantCount map[string]int
+		wantTotal int
+		wantLen   int64
+	}{
+		{
+			name:      "empty",
+			wantCount: map[string]int{"a": 0},
+		},
+		{
+			name: "add",
+			adds: []pair.Pair[string, int]{
+				pair.NewPair("a", 1), pair.NewPair("b", 2), pair.NewPair("a", 3),
+			},
+			wantCount: map[string]int{"a": 4, "b": 2},
+			wantTotal: 6,
+			wantLen:   2,
+		},
+		{
+			name: "negative",
+			adds: []pair.Pair[string, int]{
+				pair.NewPair("a", 3), pair.NewPair("b", 2), pair.NewPair("a", -1),
+			},
+			wantCount: map[string]int{"a": 2, "b": 2},
+			wantTotal: 4,
+			wantLen:   2,
+		},
+		{
+			name: "remove when not positive",
+			adds: []pair.Pair[string, int]{
+				pair.NewPair("a", 3), pair.NewPair("b", 2), pair.NewPair("a", -5),
+			},
+			wantCount: map[string]int{"a": 0, "b": 2},
+			wantTotal: 2,
+			wantLen:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCounter[string]()
+			for _, p := range tt.adds {
+				require.NoError(t, c.Add(p.Key, p.Value))
+			}
+			for k, v := range tt.wantCount {
+				assert.Equal(t, v, c.Count(k))
+			}
+			assert.Equal(t, tt.wantTotal, c.Total())
+			assert.Equal(t, tt.wantLen, c.Len())
+		})
+	}
+}
+
+func TestCounter_Elements(t *testing.T) {
+	c := newTestTreeCounter(t, 3, 1, 2, 3, 1, 3)
+	assert.Equal(t, []int{1, 2, 3}, c.Keys())
+	assert.Equal(t, []int{1, 1, 2, 3, 3, 3}, c.Elements())
+}
+
+func TestCounter_MostCommon(t *testing.T) {
+	c := newTestTreeCounter(t, 1, 2, 2, 3, 3, 3, 4, 4, 4, 4)
+	tests := []struct {
+		name string
+		n    int
+		want []pair.Pair[int, int]
+	}{
+		{
+			name: "top 2",
+			n:    2,
+			want: []pair.Pair[int, int]{pair.NewPair(4, 4), pair.NewPair(3, 3)},
+		},
+		{
+			name: "all",
+			n:    0,
+			want: []pair.Pair[int, int]{
+				pair.NewPair(4, 4), pair.NewPair(3, 3), pair.NewPair(2, 2), pair.NewPair(1, 1),
+			},
+		},
+		{
+			name: "more than len",
+			n:    10,
+			want: []pair.Pair[int, int]{
+				pair.NewPair(4, 4), pair.NewPair(3, 3), pair.NewPair(2, 2), pair.NewPair(1, 1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.MostCommon(tt.n))
+		})
+	}
+	assert.Equal(t, []pair.Pair[int, int]{}, NewCounter[int]().MostCommon(3))
+}
+
+func TestCounter_Arithmetic(t *testing.T) {
+	a := newTestTreeCounter(t, 1, 1, 1, 2, 3, 3)
+	b := newTestTreeCounter(t, 1, 2, 2, 4)
+	tests := []struct {
+		name      string
+		res       *Counter[int]
+		wantElems []int
+	}{
+		{
+			name:      "union",
+			res:       a.Union(b),
+			wantElems: []int{1, 1, 1, 2, 2, 3, 3, 4},
+		},
+		{
+			name:      "intersect",
+			res:       a.Intersect(b),
+			wantElems: []int{1, 2},
+		},
+		{
+			name:      "subtract",
+			res:       a.Subtract(b),
+			wantElems: []int{1, 1, 3, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantElems, tt.res.Elements())
+			assert.Equal(t, len(tt.wantElems), tt.res.Total())
+		})
+	}
+	// 原本的 Counter 不受影响
+	assert.Equal(t, []int{1, 1, 1, 2, 3, 3}, a.Elements())
+	assert.Equal(t, []int{1, 2, 2, 4}, b.Elements())
+}
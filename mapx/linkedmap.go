@@ -1,6 +1,18 @@
 package mapx
 
-import "github.com/WeiXinao/xkit"
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/WeiXinao/xkit"
+)
+
+var errLinkedMapNotInitialized = errors.New("xkit: LinkedMap 需要通过构造函数创建")
 
 type linkedKV[K any, V any] struct {
 	key        K
@@ -16,14 +28,7 @@ type LinkedMap[K any, V any] struct {
 
 func NewLinkedHashMap[K Hashable, V any](size int) *LinkedMap[K, V] {
 	hashmap := NewHashMap[K, *linkedKV[K, V]](size)
-	head := &linkedKV[K, V]{}
-	tail := &linkedKV[K, V]{next: head, prev: head}
-	head.prev, head.next = tail, tail
-	return &LinkedMap[K, V]{
-		m:    hashmap,
-		head: head,
-		tail: tail,
-	}
+	return newLinkedMap[K, V](hashmap)
 }
 
 func NewLinkedTreeMap[K any, V any](comparator xkit.Comparator[K]) (*LinkedMap[K, V], error) {
@@ -31,17 +36,24 @@ func NewLinkedTreeMap[K any, V any](comparator xkit.Comparator[K]) (*LinkedMap[K
 	if err != nil {
 		return nil, err
 	}
+	return newLinkedMap[K, V](treeMap), nil
+}
+
+// NewLinkedBuiltinMap 创建一个基于内置 map 的 LinkedMap
+// 适用于 string 这一类不需要实现 Hashable 的键
+func NewLinkedBuiltinMap[K comparable, V any](size int) *LinkedMap[K, V] {
+	return newLinkedMap[K, V](newBuiltinMap[K, *linkedKV[K, V]](size))
+}
+
+func newLinkedMap[K any, V any](m mapi[K, *linkedKV[K, V]]) *LinkedMap[K, V] {
 	head := &linkedKV[K, V]{}
-	tail := &linkedKV[K, V]{
-		prev: head,
-		next: head,
-	}
+	tail := &linkedKV[K, V]{next: head, prev: head}
 	head.prev, head.next = tail, tail
 	return &LinkedMap[K, V]{
-		m:    treeMap,
+		m:    m,
 		head: head,
 		tail: tail,
-	}, nil
+	}
 }
 
 func (l *LinkedMap[K, V]) Put(key K, val V) error {
@@ -73,8 +85,7 @@ func (l *LinkedMap[K, V]) Get(key K) (V, bool) {
 
 func (l *LinkedMap[K, V]) Delete(key K) (V, bool) {
 	if lk, ok := l.m.Delete(key); ok {
-		lk.prev.next = lk.next
-		lk.next.prev = lk.prev
+		l.unlink(lk)
 		l.length--
 		return lk.value, ok
 	}
@@ -103,3 +114,162 @@ func (l *LinkedMap[K, V]) Values() []V {
 func (l *LinkedMap[K, V]) Len() int64 {
 	return int64(l.length)
 }
+
+// MoveToFront 将 key 移动到最前面，key 不存在时返回 false
+func (l *LinkedMap[K, V]) MoveToFront(key K) bool {
+	lk, ok := l.m.Get(key)
+	if !ok {
+		return false
+	}
+	l.unlink(lk)
+	l.linkAfter(lk, l.head)
+	return true
+}
+
+// MoveToBack 将 key 移动到最后面，key 不存在时返回 false
+func (l *LinkedMap[K, V]) MoveToBack(key K) bool {
+	lk, ok := l.m.Get(key)
+	if !ok {
+		return false
+	}
+	l.unlink(lk)
+	l.linkAfter(lk, l.tail.prev)
+	return true
+}
+
+// PopFirst 删除并返回最前面的键值对，LinkedMap 为空时返回 false
+func (l *LinkedMap[K, V]) PopFirst() (K, V, bool) {
+	return l.pop(l.head.next)
+}
+
+// PopLast 删除并返回最后面的键值对，LinkedMap 为空时返回 false
+func (l *LinkedMap[K, V]) PopLast() (K, V, bool) {
+	return l.pop(l.tail.prev)
+}
+
+func (l *LinkedMap[K, V]) pop(lk *linkedKV[K, V]) (K, V, bool) {
+	if l.length == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	val, _ := l.Delete(lk.key)
+	return lk.key, val, true
+}
+
+func (l *LinkedMap[K, V]) unlink(lk *linkedKV[K, V]) {
+	lk.prev.next = lk.next
+	lk.next.prev = lk.prev
+}
+
+func (l *LinkedMap[K, V]) linkAfter(lk *linkedKV[K, V], prev *linkedKV[K, V]) {
+	lk.prev, lk.next = prev, prev.next
+	lk.prev.next, lk.next.prev = lk, lk
+}
+
+// MarshalJSON 将 LinkedMap 序列化为 JSON 对象，键的顺序和插入顺序一致
+// 键必须是字符串、整数或者实现了 encoding.TextMarshaler 接口
+func (l *LinkedMap[K, V]) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for cur := l.head.next; cur != l.tail; cur = cur.next {
+		if cur != l.head.next {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSONKey(cur.key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(cur.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 按照 JSON 对象中键的顺序将键值对放入 LinkedMap
+// 已经存在的键会保持原本的位置，只更新值
+// 注意 LinkedMap 必须已经通过构造函数创建
+func (l *LinkedMap[K, V]) UnmarshalJSON(data []byte) error {
+	if l.m == nil {
+		return errLinkedMapNotInitialized
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("xkit: LinkedMap 只能从 JSON 对象反序列化，实际为 %v", tok)
+	}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		key, err := unmarshalJSONKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+		var val V
+		if err = dec.Decode(&val); err != nil {
+			return err
+		}
+		if err = l.Put(key, val); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+func marshalJSONKey(key any) ([]byte, error) {
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(string(text))
+	}
+	rv := reflect.ValueOf(key)
+	switch rv.Kind() {
+	case reflect.String:
+		return json.Marshal(rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Marshal(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Marshal(strconv.FormatUint(rv.Uint(), 10))
+	}
+	return nil, fmt.Errorf("xkit: 不支持的 JSON 键类型 %T", key)
+}
+
+func unmarshalJSONKey[K any](s string) (K, error) {
+	var key K
+	if tu, ok := any(&key).(encoding.TextUnmarshaler); ok {
+		return key, tu.UnmarshalText([]byte(s))
+	}
+	rv := reflect.ValueOf(&key).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return key, err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return key, err
+		}
+		rv.SetUint(n)
+	default:
+		return key, fmt.Errorf("xkit: 不支持的 JSON 键类型 %T", key)
+	}
+	return key, nil
+}
@@ -1,6 +1,7 @@
 package mapx
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/WeiXinao/xkit"
 	"testing"
@@ -489,3 +490,181 @@ func TestLinkedMap_Len(t *testing.T) {
 		})
 	}
 }
+
+func TestLinkedMap_NewLinkedBuiltinMap(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, int](0)
+	assert.Equal(t, []string{}, m.Keys())
+	for i, k := range []string{"c", "a", "b"} {
+		assert.NoError(t, m.Put(k, i))
+	}
+	assert.NoError(t, m.Put("a", 10))
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{0, 10, 2}, m.Values())
+	val, ok := m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, val)
+	assert.Equal(t, []string{"c", "b"}, m.Keys())
+	assert.Equal(t, int64(2), m.Len())
+}
+
+func TestLinkedMap_Move(t *testing.T) {
+	testCases := []struct {
+		name     string
+		move     func(m *LinkedMap[string, int]) bool
+		wantOk   bool
+		wantKeys []string
+	}{
+		{
+			name: "move to front",
+			move: func(m *LinkedMap[string, int]) bool {
+				return m.MoveToFront("c")
+			},
+			wantOk:   true,
+			wantKeys: []string{"c", "a", "b"},
+		},
+		{
+			name: "move first to front",
+			move: func(m *LinkedMap[string, int]) bool {
+				return m.MoveToFront("a")
+			},
+			wantOk:   true,
+			wantKeys: []string{"a", "b", "c"},
+		},
+		{
+			name: "move to back",
+			move: func(m *LinkedMap[string, int]) bool {
+				return m.MoveToBack("a")
+			},
+			wantOk:   true,
+			wantKeys: []string{"b", "c", "a"},
+		},
+		{
+			name: "move not exist key",
+			move: func(m *LinkedMap[string, int]) bool {
+				return m.MoveToBack("d") || m.MoveToFront("d")
+			},
+			wantKeys: []string{"a", "b", "c"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewLinkedBuiltinMap[string, int](3)
+			for i, k := range []string{"a", "b", "c"} {
+				assert.NoError(t, m.Put(k, i))
+			}
+			assert.Equal(t, tc.wantOk, tc.move(m))
+			assert.Equal(t, tc.wantKeys, m.Keys())
+		})
+	}
+}
+
+func TestLinkedMap_Pop(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, int](3)
+	for i, k := range []string{"a", "b", "c"} {
+		assert.NoError(t, m.Put(k, i))
+	}
+	k, v, ok := m.PopFirst()
+	assert.True(t, ok)
+	assert.Equal(t, "a", k)
+	assert.Equal(t, 0, v)
+	k, v, ok = m.PopLast()
+	assert.True(t, ok)
+	assert.Equal(t, "c", k)
+	assert.Equal(t, 2, v)
+	k, _, ok = m.PopLast()
+	assert.True(t, ok)
+	assert.Equal(t, "b", k)
+	_, _, ok = m.PopFirst()
+	assert.False(t, ok)
+	_, _, ok = m.PopLast()
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), m.Len())
+}
+
+func TestLinkedMap_MarshalJSON(t *testing.T) {
+	m := NewLinkedBuiltinMap[string, any](3)
+	assert.NoError(t, m.Put("z", 1))
+	assert.NoError(t, m.Put("a", "str"))
+	assert.NoError(t, m.Put("m", []int{1, 2}))
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z":1,"a":"str","m":[1,2]}`, string(data))
+
+	intKeys := NewLinkedBuiltinMap[int, string](2)
+	assert.NoError(t, intKeys.Put(2, "b"))
+	assert.NoError(t, intKeys.Put(-1, "a"))
+	data, err = json.Marshal(intKeys)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"2":"b","-1":"a"}`, string(data))
+
+	empty := NewLinkedBuiltinMap[string, int](0)
+	data, err = json.Marshal(empty)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+
+	structKeys, err := NewLinkedTreeMap[testStructKey, int](func(src, dst testStructKey) int {
+		return src.id - dst.id
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, structKeys.Put(testStructKey{id: 1}, 1))
+	_, err = json.Marshal(structKeys)
+	assert.Error(t, err)
+}
+
+type testStructKey struct {
+	id int
+}
+
+func TestLinkedMap_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		wantKeys []string
+		wantVals []int
+		wantErr  bool
+	}{
+		{
+			name:     "keep order",
+			data:     `{"z":1,"a":2,"m":3}`,
+			wantKeys: []string{"z", "a", "m"},
+			wantVals: []int{1, 2, 3},
+		},
+		{
+			name:     "empty",
+			data:     `{}`,
+			wantKeys: []string{},
+			wantVals: []int{},
+		},
+		{
+			name:    "not object",
+			data:    `[1,2]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			data:    `{"a":"str"}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewLinkedBuiltinMap[string, int](0)
+			err := json.Unmarshal([]byte(tc.data), m)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantKeys, m.Keys())
+			assert.Equal(t, tc.wantVals, m.Values())
+		})
+	}
+
+	intKeys := NewLinkedBuiltinMap[uint8, string](0)
+	assert.NoError(t, json.Unmarshal([]byte(`{"3":"c","1":"a"}`), intKeys))
+	assert.Equal(t, []uint8{3, 1}, intKeys.Keys())
+	assert.Error(t, json.Unmarshal([]byte(`{"300":"c"}`), intKeys))
+
+	var zero LinkedMap[string, int]
+	assert.Equal(t, errLinkedMapNotInitialized, json.Unmarshal([]byte(`{}`), &zero))
+}
@@ -0,0 +1,464 @@
+package mapx
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+const (
+	hamtBits  = 5
+	hamtWidth = 1 << hamtBits
+	hamtMask  = hamtWidth - 1
+	// hamtMaxShift 超过这个位移之后，哈希值已经被用完了，只能用冲突节点来存储
+	hamtMaxShift = 64
+)
+
+// hamtOwner 用于标记节点属于哪个 TransientMap
+// 只有属于当前 TransientMap 的节点才可以原地修改
+type hamtOwner struct {
+	// 避免零大小的结构体的指针相等
+	_ byte
+}
+
+type hamtEntry[K any, V any] struct {
+	hash  uint64
+	key   K
+	value V
+}
+
+// hamtSlot 要么是一个键值对，要么是一个子节点
+type hamtSlot[K any, V any] struct {
+	entry *hamtEntry[K, V]
+	node  *hamtNode[K, V]
+}
+
+// hamtNode 是 HAMT 中的节点
+// 普通节点使用 bitmap 压缩存储 32 个分支
+// 当哈希值被用完的时候，节点退化为冲突节点，所有的键值对都放在 collisions 里面
+type hamtNode[K any, V any] struct {
+	owner      *hamtOwner
+	bitmap     uint32
+	slots      []hamtSlot[K, V]
+	collisions []*hamtEntry[K, V]
+}
+
+// hamtHasher 抽象了 comparable 和 Hashable 两种键的哈希与比较
+type hamtHasher[K any] struct {
+	hash  func(key K) uint64
+	equal func(src, dst K) bool
+}
+
+// PersistentMap 是基于 HAMT（Hash Array Mapped Trie）实现的不可变 Map
+// 所有的修改操作都会返回一个新的版本，新旧版本之间共享没有被修改的部分
+// 因此 PersistentMap 可以被多个 goroutine 安全地并发读取
+// 例如可以配合 atomicx.Value 发布新的版本，而读者依旧持有旧的快照
+type PersistentMap[K any, V any] struct {
+	root   *hamtNode[K, V]
+	size   int
+	hasher *hamtHasher[K]
+}
+
+// NewPersistentHashMap 创建一个键实现了 Hashable 的 PersistentMap
+// 哈希值来自于 Code 方法，键的比较使用 Equals 方法
+func NewPersistentHashMap[K Hashable, V any]() *PersistentMap[K, V] {
+	return &PersistentMap[K, V]{
+		hasher: &hamtHasher[K]{
+			hash: func(key K) uint64 {
+				return key.Code()
+			},
+			equal: func(src, dst K) bool {
+				return src.Equals(dst)
+			},
+		},
+	}
+}
+
+// NewPersistentBuiltinMap 创建一个键为 comparable 的 PersistentMap
+// 哈希值使用 maphash.Comparable 计算，和 == 的语义一致：
+// 例如 0.0 和 -0.0 是同一个键，接口中的指针按照地址而不是指向的内容比较
+func NewPersistentBuiltinMap[K comparable, V any]() *PersistentMap[K, V] {
+	seed := maphash.MakeSeed()
+	return &PersistentMap[K, V]{
+		hasher: &hamtHasher[K]{
+			hash: func(key K) uint64 {
+				return maphash.Comparable(seed, key)
+			},
+			equal: func(src, dst K) bool {
+				return src == dst
+			},
+		},
+	}
+}
+
+// Get 返回 key 对应的值，时间复杂度为 O(log32 n)
+func (m *PersistentMap[K, V]) Get(key K) (V, bool) {
+	hash := m.hasher.hash(key)
+	node := m.root
+	for shift := uint(0); node != nil; shift += hamtBits {
+		if shift >= hamtMaxShift {
+			for _, e := range node.collisions {
+				if m.hasher.equal(e.key, key) {
+					return e.value, true
+				}
+			}
+			break
+		}
+		bit := hamtBit(hash, shift)
+		if node.bitmap&bit == 0 {
+			break
+		}
+		slot := node.slots[node.index(bit)]
+		if slot.node == nil {
+			if slot.entry.hash == hash && m.hasher.equal(slot.entry.key, key) {
+				return slot.entry.value, true
+			}
+			break
+		}
+		node = slot.node
+	}
+	var v V
+	return v, false
+}
+
+// Contains 判断 key 是否存在
+func (m *PersistentMap[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Assoc 返回一个新的版本，其中 key 对应的值为 val
+// 原本的 PersistentMap 不会被修改
+func (m *PersistentMap[K, V]) Assoc(key K, val V) *PersistentMap[K, V] {
+	e := &hamtEntry[K, V]{hash: m.hasher.hash(key), key: key, value: val}
+	root, added := m.root.assoc(nil, m.hasher, 0, e)
+	res := &PersistentMap[K, V]{root: root, size: m.size, hasher: m.hasher}
+	if added {
+		res.size++
+	}
+	return res
+}
+
+// Dissoc 返回一个新的版本，其中不再包含 key
+// 如果 key 本来就不存在，那么返回 PersistentMap 本身
+func (m *PersistentMap[K, V]) Dissoc(key K) *PersistentMap[K, V] {
+	root, removed := m.root.dissoc(nil, m.hasher, 0, m.hasher.hash(key), key)
+	if !removed {
+		return m
+	}
+	return &PersistentMap[K, V]{root: root, size: m.size - 1, hasher: m.hasher}
+}
+
+// Len 返回键值对数量
+func (m *PersistentMap[K, V]) Len() int64 {
+	return int64(m.size)
+}
+
+// Range 遍历所有的键值对，fn 返回 false 的时候中断遍历
+// 注意：遍历的顺序取决于键的哈希值
+func (m *PersistentMap[K, V]) Range(fn func(key K, val V) bool) {
+	m.root.each(fn)
+}
+
+// Keys 返回所有的键，顺序取决于键的哈希值
+func (m *PersistentMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	m.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values 返回所有的值，顺序和 Keys 一致
+func (m *PersistentMap[K, V]) Values() []V {
+	vals := make([]V, 0, m.size)
+	m.Range(func(_ K, val V) bool {
+		vals = append(vals, val)
+		return true
+	})
+	return vals
+}
+
+// Equal 判断两个 PersistentMap 是否包含相同的键值对
+// equal 用于比较值，如果两个版本共享同一个根节点，那么不需要逐个比较
+func (m *PersistentMap[K, V]) Equal(other *PersistentMap[K, V], equal func(src, dst V) bool) bool {
+	if m == other || m.root == other.root {
+		return true
+	}
+	if m.size != other.size {
+		return false
+	}
+	res := true
+	m.Range(func(key K, val V) bool {
+		otherVal, ok := other.Get(key)
+		res = ok && equal(val, otherVal)
+		return res
+	})
+	return res
+}
+
+// Transient 返回一个基于当前版本的 TransientMap
+// 用于批量构建新的版本，避免每次修改都复制路径上的节点
+func (m *PersistentMap[K, V]) Transient() *TransientMap[K, V] {
+	return &TransientMap[K, V]{
+		root:   m.root,
+		size:   m.size,
+		hasher: m.hasher,
+		owner:  &hamtOwner{},
+	}
+}
+
+// TransientMap 是 PersistentMap 的可变版本
+// 它会原地修改自己创建的节点，而不会影响到任何 PersistentMap
+// TransientMap 不是并发安全的
+type TransientMap[K any, V any] struct {
+	root   *hamtNode[K, V]
+	size   int
+	hasher *hamtHasher[K]
+	owner  *hamtOwner
+}
+
+// Put 设置 key 对应的值
+func (t *TransientMap[K, V]) Put(key K, val V) error {
+	e := &hamtEntry[K, V]{hash: t.hasher.hash(key), key: key, value: val}
+	root, added := t.root.assoc(t.owner, t.hasher, 0, e)
+	t.root = root
+	if added {
+		t.size++
+	}
+	return nil
+}
+
+// Get 返回 key 对应的值
+func (t *TransientMap[K, V]) Get(key K) (V, bool) {
+	return t.persistent().Get(key)
+}
+
+// Delete 删除 key，返回被删除的值
+func (t *TransientMap[K, V]) Delete(key K) (V, bool) {
+	val, ok := t.Get(key)
+	if !ok {
+		return val, false
+	}
+	t.root, _ = t.root.dissoc(t.owner, t.hasher, 0, t.hasher.hash(key), key)
+	t.size--
+	return val, true
+}
+
+// Keys 返回所有的键
+func (t *TransientMap[K, V]) Keys() []K {
+	return t.persistent().Keys()
+}
+
+// Values 返回所有的值
+func (t *TransientMap[K, V]) Values() []V {
+	return t.persistent().Values()
+}
+
+// Len 返回键值对数量
+func (t *TransientMap[K, V]) Len() int64 {
+	return int64(t.size)
+}
+
+// Persistent 返回当前内容的 PersistentMap
+// 调用之后 TransientMap 依旧可以继续使用，但是后续的修改不会影响返回的 PersistentMap
+func (t *TransientMap[K, V]) Persistent() *PersistentMap[K, V] {
+	res := t.persistent()
+	t.owner = &hamtOwner{}
+	return res
+}
+
+func (t *TransientMap[K, V]) persistent() *PersistentMap[K, V] {
+	return &PersistentMap[K, V]{root: t.root, size: t.size, hasher: t.hasher}
+}
+
+func hamtBit(hash uint64, shift uint) uint32 {
+	return 1 << ((hash >> shift) & hamtMask)
+}
+
+func (n *hamtNode[K, V]) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// editable 返回一个可以原地修改的节点
+// 如果节点属于 owner，那么直接返回节点本身，否则复制一份
+func (n *hamtNode[K, V]) editable(owner *hamtOwner) *hamtNode[K, V] {
+	if owner != nil && n.owner == owner {
+		return n
+	}
+	res := &hamtNode[K, V]{owner: owner, bitmap: n.bitmap}
+	if n.slots != nil {
+		res.slots = make([]hamtSlot[K, V], len(n.slots), len(n.slots)+1)
+		copy(res.slots, n.slots)
+	}
+	if n.collisions != nil {
+		res.collisions = make([]*hamtEntry[K, V], len(n.collisions), len(n.collisions)+1)
+		copy(res.collisions, n.collisions)
+	}
+	return res
+}
+
+// assoc 插入或者更新键值对，返回新的节点以及是否新增了键
+func (n *hamtNode[K, V]) assoc(owner *hamtOwner, hasher *hamtHasher[K],
+	shift uint, e *hamtEntry[K, V]) (*hamtNode[K, V], bool) {
+	if n == nil {
+		n = &hamtNode[K, V]{owner: owner}
+	}
+	if shift >= hamtMaxShift {
+		for i, old := range n.collisions {
+			if hasher.equal(old.key, e.key) {
+				res := n.editable(owner)
+				res.collisions[i] = e
+				return res, false
+			}
+		}
+		res := n.editable(owner)
+		res.collisions = append(res.collisions, e)
+		return res, true
+	}
+
+	bit := hamtBit(e.hash, shift)
+	idx := n.index(bit)
+	if n.bitmap&bit == 0 {
+		res := n.editable(owner)
+		res.bitmap |= bit
+		res.slots = append(res.slots, hamtSlot[K, V]{})
+		copy(res.slots[idx+1:], res.slots[idx:])
+		res.slots[idx] = hamtSlot[K, V]{entry: e}
+		return res, true
+	}
+
+	slot := n.slots[idx]
+	if slot.node != nil {
+		child, added := slot.node.assoc(owner, hasher, shift+hamtBits, e)
+		if child == slot.node {
+			return n, added
+		}
+		res := n.editable(owner)
+		res.slots[idx] = hamtSlot[K, V]{node: child}
+		return res, added
+	}
+
+	res := n.editable(owner)
+	if slot.entry.hash == e.hash && hasher.equal(slot.entry.key, e.key) {
+		res.slots[idx] = hamtSlot[K, V]{entry: e}
+		return res, false
+	}
+	res.slots[idx] = hamtSlot[K, V]{node: mergeHamtEntries(owner, shift+hamtBits, slot.entry, e)}
+	return res, true
+}
+
+// mergeHamtEntries 将两个落在同一个分支的键值对下沉到新的子节点
+func mergeHamtEntries[K any, V any](owner *hamtOwner, shift uint, src, dst *hamtEntry[K, V]) *hamtNode[K, V] {
+	if shift >= hamtMaxShift {
+		return &hamtNode[K, V]{owner: owner, collisions: []*hamtEntry[K, V]{src, dst}}
+	}
+	srcBit, dstBit := hamtBit(src.hash, shift), hamtBit(dst.hash, shift)
+	if srcBit == dstBit {
+		return &hamtNode[K, V]{
+			owner:  owner,
+			bitmap: srcBit,
+			slots:  []hamtSlot[K, V]{{node: mergeHamtEntries(owner, shift+hamtBits, src, dst)}},
+		}
+	}
+	slots := []hamtSlot[K, V]{{entry: src}, {entry: dst}}
+	if dstBit < srcBit {
+		slots[0], slots[1] = slots[1], slots[0]
+	}
+	return &hamtNode[K, V]{owner: owner, bitmap: srcBit | dstBit, slots: slots}
+}
+
+// dissoc 删除键，返回新的节点以及是否真的删除了
+// 如果删除之后节点为空，那么返回 nil
+func (n *hamtNode[K, V]) dissoc(owner *hamtOwner, hasher *hamtHasher[K],
+	shift uint, hash uint64, key K) (*hamtNode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	if shift >= hamtMaxShift {
+		for i, old := range n.collisions {
+			if hasher.equal(old.key, key) {
+				if len(n.collisions) == 1 {
+					return nil, true
+				}
+				res := n.editable(owner)
+				res.collisions = append(res.collisions[:i], res.collisions[i+1:]...)
+				return res, true
+			}
+		}
+		return n, false
+	}
+
+	bit := hamtBit(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	idx := n.index(bit)
+	slot := n.slots[idx]
+	if slot.node == nil {
+		if slot.entry.hash != hash || !hasher.equal(slot.entry.key, key) {
+			return n, false
+		}
+		return n.removeSlot(owner, bit, idx), true
+	}
+
+	child, removed := slot.node.dissoc(owner, hasher, shift+hamtBits, hash, key)
+	if !removed {
+		return n, false
+	}
+	if child == nil {
+		return n.removeSlot(owner, bit, idx), true
+	}
+	res := n.editable(owner)
+	// 子节点只剩下一个键值对的时候，将它提升到当前节点，保持树的紧凑
+	if e := child.single(); e != nil {
+		res.slots[idx] = hamtSlot[K, V]{entry: e}
+	} else {
+		res.slots[idx] = hamtSlot[K, V]{node: child}
+	}
+	return res, true
+}
+
+func (n *hamtNode[K, V]) removeSlot(owner *hamtOwner, bit uint32, idx int) *hamtNode[K, V] {
+	if len(n.slots) == 1 {
+		return nil
+	}
+	res := n.editable(owner)
+	res.bitmap &^= bit
+	res.slots = append(res.slots[:idx], res.slots[idx+1:]...)
+	return res
+}
+
+// single 如果节点只包含一个键值对，那么返回它
+func (n *hamtNode[K, V]) single() *hamtEntry[K, V] {
+	if len(n.collisions) == 1 {
+		return n.collisions[0]
+	}
+	if len(n.slots) == 1 && n.slots[0].node == nil {
+		return n.slots[0].entry
+	}
+	return nil
+}
+
+func (n *hamtNode[K, V]) each(fn func(key K, val V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, e := range n.collisions {
+		if !fn(e.key, e.value) {
+			return false
+		}
+	}
+	for _, slot := range n.slots {
+		if slot.node != nil {
+			if !slot.node.each(fn) {
+				return false
+			}
+			continue
+		}
+		if !fn(slot.entry.key, slot.entry.value) {
+			return false
+		}
+	}
+	return true
+}
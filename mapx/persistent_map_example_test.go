@@ -0,0 +1,23 @@
+package mapx_test
+
+import (
+	"fmt"
+
+	"github.com/WeiXinao/xkit/mapx"
+	"github.com/WeiXinao/xkit/syncx/atomicx"
+)
+
+func ExampleNewPersistentBuiltinMap() {
+	routes := atomicx.NewValueOf(mapx.NewPersistentBuiltinMap[string, string]())
+	// 读者持有的是某一个版本的快照
+	snapshot := routes.Load()
+	// 写者发布新的版本
+	routes.Store(snapshot.Assoc("/user", "user-service"))
+
+	fmt.Println(snapshot.Len())
+	val, _ := routes.Load().Get("/user")
+	fmt.Println(val)
+	// Output:
+	// 0
+	// user-service
+}
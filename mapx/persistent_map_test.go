@@ -0,0 +1,234 @@
+package mapx
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 借助 testData 来验证一下 TransientMap 实现了 mapi 接口
+var _ mapi[testData, int] = &TransientMap[testData, int]{}
+
+func TestPersistentMap_AssocDissoc(t *testing.T) {
+	testCases := []struct {
+		name     string
+		m        func() *PersistentMap[string, int]
+		assoc    map[string]int
+		dissoc   []string
+		wantKeys []string
+		wantVals []int
+	}{
+		{
+			name: "empty",
+			m: func() *PersistentMap[string, int] {
+				return NewPersistentBuiltinMap[string, int]()
+			},
+			wantKeys: []string{},
+			wantVals: []int{},
+		},
+		{
+			name: "assoc",
+			m: func() *PersistentMap[string, int] {
+				return NewPersistentBuiltinMap[string, int]()
+			},
+			assoc:    map[string]int{"a": 1, "b": 2, "c": 3},
+			wantKeys: []string{"a", "b", "c"},
+			wantVals: []int{1, 2, 3},
+		},
+		{
+			name: "assoc existing key",
+			m: func() *PersistentMap[string, int] {
+				return NewPersistentBuiltinMap[string, int]().Assoc("a", 1)
+			},
+			assoc:    map[string]int{"a": 11},
+			wantKeys: []string{"a"},
+			wantVals: []int{11},
+		},
+		{
+			name: "dissoc",
+			m: func() *PersistentMap[string, int] {
+				return NewPersistentBuiltinMap[string, int]().Assoc("a", 1).Assoc("b", 2)
+			},
+			dissoc:   []string{"a", "c"},
+			wantKeys: []string{"b"},
+			wantVals: []int{2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.m()
+			for k, v := range tc.assoc {
+				m = m.Assoc(k, v)
+			}
+			for _, k := range tc.dissoc {
+				m = m.Dissoc(k)
+			}
+			assert.ElementsMatch(t, tc.wantKeys, m.Keys())
+			assert.ElementsMatch(t, tc.wantVals, m.Values())
+			assert.Equal(t, int64(len(tc.wantKeys)), m.Len())
+			for i, k := range tc.wantKeys {
+				v, ok := m.Get(k)
+				assert.True(t, ok)
+				assert.Equal(t, tc.wantVals[i], v)
+			}
+		})
+	}
+}
+
+func TestPersistentMap_StructureSharing(t *testing.T) {
+	v1 := NewPersistentBuiltinMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		v1 = v1.Assoc(i, i)
+	}
+	v2 := v1.Assoc(1000, 1000).Dissoc(0).Assoc(1, 100)
+
+	// 旧版本不受影响
+	assert.Equal(t, int64(1000), v1.Len())
+	val, ok := v1.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, 0, val)
+	val, _ = v1.Get(1)
+	assert.Equal(t, 1, val)
+	assert.False(t, v1.Contains(1000))
+
+	assert.Equal(t, int64(1000), v2.Len())
+	assert.False(t, v2.Contains(0))
+	val, _ = v2.Get(1)
+	assert.Equal(t, 100, val)
+	assert.True(t, v2.Contains(1000))
+
+	// 删除不存在的键返回自身
+	assert.Same(t, v2, v2.Dissoc(-1))
+}
+
+func TestPersistentMap_HashCollision(t *testing.T) {
+	// testData 的哈希值只有 10 种，大量的键会落入冲突节点
+	m := NewPersistentHashMap[testData, int]()
+	for i := 0; i < 100; i++ {
+		m = m.Assoc(newTestData(i), i)
+	}
+	assert.Equal(t, int64(100), m.Len())
+	for i := 0; i < 100; i++ {
+		val, ok := m.Get(newTestData(i))
+		assert.True(t, ok)
+		assert.Equal(t, i, val)
+	}
+	for i := 0; i < 100; i += 2 {
+		m = m.Dissoc(newTestData(i))
+	}
+	assert.Equal(t, int64(50), m.Len())
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i%2 == 1, m.Contains(newTestData(i)))
+	}
+}
+
+// 键的哈希值必须和 == 的语义一致，否则相等的键会落在不同的位置
+func TestPersistentBuiltinMap_KeySemantics(t *testing.T) {
+	t.Run("float zero", func(t *testing.T) {
+		negZero := math.Copysign(0, -1)
+		m := NewPersistentBuiltinMap[float64, string]().Assoc(0.0, "zero")
+		val, ok := m.Get(negZero)
+		assert.True(t, ok)
+		assert.Equal(t, "zero", val)
+		m = m.Assoc(negZero, "negative zero")
+		assert.Equal(t, int64(1), m.Len())
+		val, _ = m.Get(0.0)
+		assert.Equal(t, "negative zero", val)
+	})
+
+	t.Run("pointer in interface", func(t *testing.T) {
+		type user struct {
+			Name string
+		}
+		p := &user{Name: "Tom"}
+		m := NewPersistentBuiltinMap[any, int]().Assoc(p, 1)
+		// 修改指针指向的内容不会影响键
+		p.Name = "Jerry"
+		val, ok := m.Get(p)
+		assert.True(t, ok)
+		assert.Equal(t, 1, val)
+		// 内容相同但是地址不同的指针是不同的键
+		assert.False(t, m.Contains(&user{Name: "Jerry"}))
+		assert.Equal(t, int64(1), m.Assoc(p, 2).Len())
+	})
+
+	t.Run("interface holding float", func(t *testing.T) {
+		m := NewPersistentBuiltinMap[any, int]().Assoc(0.0, 1)
+		assert.True(t, m.Contains(math.Copysign(0, -1)))
+		assert.False(t, m.Contains(float32(0)))
+	})
+}
+
+func TestPersistentMap_Equal(t *testing.T) {
+	eq := func(src, dst int) bool {
+		return src == dst
+	}
+	m1 := NewPersistentBuiltinMap[string, int]().Assoc("a", 1).Assoc("b", 2)
+	m2 := NewPersistentBuiltinMap[string, int]().Assoc("b", 2).Assoc("a", 1)
+	assert.True(t, m1.Equal(m1, eq))
+	assert.True(t, m1.Equal(m2, eq))
+	assert.False(t, m1.Equal(m2.Assoc("a", 3), eq))
+	assert.False(t, m1.Equal(m2.Assoc("c", 3), eq))
+	assert.False(t, m1.Equal(m2.Dissoc("a").Assoc("c", 1), eq))
+}
+
+func TestTransientMap(t *testing.T) {
+	base := NewPersistentBuiltinMap[int, int]().Assoc(1, 1).Assoc(2, 2)
+	tm := base.Transient()
+	for i := 3; i <= 100; i++ {
+		assert.NoError(t, tm.Put(i, i))
+	}
+	val, ok := tm.Delete(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	_, ok = tm.Delete(1)
+	assert.False(t, ok)
+	assert.Equal(t, int64(99), tm.Len())
+
+	snapshot := tm.Persistent()
+	assert.NoError(t, tm.Put(2, 200))
+	assert.NoError(t, tm.Put(101, 101))
+
+	// 快照与原始版本都不受后续修改的影响
+	val, _ = snapshot.Get(2)
+	assert.Equal(t, 2, val)
+	assert.False(t, snapshot.Contains(101))
+	assert.Equal(t, int64(99), snapshot.Len())
+	assert.Equal(t, int64(2), base.Len())
+	assert.True(t, base.Contains(1))
+
+	val, _ = tm.Get(2)
+	assert.Equal(t, 200, val)
+	assert.Equal(t, int64(100), tm.Len())
+}
+
+func TestPersistentMap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	want := make(map[int]int)
+	m := NewPersistentBuiltinMap[int, int]()
+	tm := NewPersistentBuiltinMap[int, int]().Transient()
+	for i := 0; i < 20000; i++ {
+		k := r.Intn(2000)
+		if r.Intn(3) == 0 {
+			delete(want, k)
+			m = m.Dissoc(k)
+			tm.Delete(k)
+			continue
+		}
+		want[k] = i
+		m = m.Assoc(k, i)
+		_ = tm.Put(k, i)
+	}
+	assert.Equal(t, int64(len(want)), m.Len())
+	assert.Equal(t, int64(len(want)), tm.Len())
+	for k, v := range want {
+		val, ok := m.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, val)
+	}
+	assert.True(t, m.Equal(tm.Persistent(), func(src, dst int) bool {
+		return src == dst
+	}))
+}
@@ -0,0 +1,143 @@
+package mapx
+
+import (
+	"errors"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/internal/tree"
+)
+
+var errRangeMapInvalidRange = errors.New("xkit: RangeMap 区间的下界必须小于上界")
+
+// RangeEntry 是 RangeMap 中的一个区间 [Lo, Hi) 以及它对应的值
+type RangeEntry[K any, V any] struct {
+	Lo    K
+	Hi    K
+	Value V
+}
+
+type rangeValue[K any, V any] struct {
+	hi  K
+	val V
+}
+
+// RangeMap 将互不重叠的左闭右开区间 [lo, hi) 映射到值
+// 底层是以区间下界为键的红黑树
+// 相邻并且值相等的区间会被合并为一个区间
+type RangeMap[K any, V comparable] struct {
+	tree    *tree.RBTree[K, rangeValue[K, V]]
+	compare xkit.Comparator[K]
+}
+
+// NewRangeMap 创建一个 RangeMap
+// 需要注意比较器 compare 不能为 nil
+func NewRangeMap[K any, V comparable](compare xkit.Comparator[K]) (*RangeMap[K, V], error) {
+	if compare == nil {
+		return nil, errTreeMapComparatorIsNull
+	}
+	return &RangeMap[K, V]{
+		tree:    tree.NewRBTree[K, rangeValue[K, V]](compare),
+		compare: compare,
+	}, nil
+}
+
+// Put 将区间 [lo, hi) 映射到 val
+// 与之重叠的已有区间会被截断或者拆分，与之相邻并且值相等的区间会被合并
+// 如果 lo >= hi 会返回错误
+func (r *RangeMap[K, V]) Put(lo, hi K, val V) error {
+	if r.compare(lo, hi) >= 0 {
+		return errRangeMapInvalidRange
+	}
+	r.remove(lo, hi)
+	if prevLo, prev, err := r.tree.Floor(lo); err == nil &&
+		r.compare(prev.hi, lo) == 0 && prev.val == val {
+		r.tree.Delete(prevLo)
+		lo = prevLo
+	}
+	if next, err := r.tree.Find(hi); err == nil && next.val == val {
+		r.tree.Delete(hi)
+		hi = next.hi
+	}
+	return r.tree.Add(lo, rangeValue[K, V]{hi: hi, val: val})
+}
+
+// Get 返回包含 key 的区间对应的值
+// 如果 key 不在任何区间中，返回 false
+func (r *RangeMap[K, V]) Get(key K) (V, bool) {
+	entry, ok := r.GetEntry(key)
+	return entry.Value, ok
+}
+
+// GetEntry 返回包含 key 的区间
+// 如果 key 不在任何区间中，返回 false
+func (r *RangeMap[K, V]) GetEntry(key K) (RangeEntry[K, V], bool) {
+	lo, rv, err := r.tree.Floor(key)
+	if err != nil || r.compare(key, rv.hi) >= 0 {
+		return RangeEntry[K, V]{}, false
+	}
+	return RangeEntry[K, V]{Lo: lo, Hi: rv.hi, Value: rv.val}, true
+}
+
+// Remove 移除区间 [lo, hi) 上的映射
+// 与之重叠的区间会被截断或者拆分
+// 如果 lo >= hi 会返回错误
+func (r *RangeMap[K, V]) Remove(lo, hi K) error {
+	if r.compare(lo, hi) >= 0 {
+		return errRangeMapInvalidRange
+	}
+	r.remove(lo, hi)
+	return nil
+}
+
+func (r *RangeMap[K, V]) remove(lo, hi K) {
+	// 跨过 lo 的区间
+	if prevLo, prev, err := r.tree.Floor(lo); err == nil && r.compare(prev.hi, lo) > 0 {
+		r.tree.Delete(prevLo)
+		if r.compare(prevLo, lo) < 0 {
+			_ = r.tree.Add(prevLo, rangeValue[K, V]{hi: lo, val: prev.val})
+		}
+		if r.compare(prev.hi, hi) > 0 {
+			_ = r.tree.Add(hi, rangeValue[K, V]{hi: prev.hi, val: prev.val})
+			return
+		}
+	}
+	// 下界落在 [lo, hi) 中的区间
+	for {
+		nextLo, next, err := r.tree.Ceiling(lo)
+		if err != nil || r.compare(nextLo, hi) >= 0 {
+			return
+		}
+		r.tree.Delete(nextLo)
+		if r.compare(next.hi, hi) > 0 {
+			_ = r.tree.Add(hi, rangeValue[K, V]{hi: next.hi, val: next.val})
+			return
+		}
+	}
+}
+
+// Span 返回能够覆盖所有区间的最小区间
+// 如果 RangeMap 为空，返回 false
+func (r *RangeMap[K, V]) Span() (K, K, bool) {
+	lo, _, err := r.tree.Min()
+	if err != nil {
+		var k K
+		return k, k, false
+	}
+	_, last, _ := r.tree.Max()
+	return lo, last.hi, true
+}
+
+// Entries 按照区间下界从小到大返回所有的区间
+func (r *RangeMap[K, V]) Entries() []RangeEntry[K, V] {
+	keys, vals := r.tree.KeyValues()
+	res := make([]RangeEntry[K, V], 0, len(keys))
+	for i := range keys {
+		res = append(res, RangeEntry[K, V]{Lo: keys[i], Hi: vals[i].hi, Value: vals[i].val})
+	}
+	return res
+}
+
+// Len 返回区间的数量
+func (r *RangeMap[K, V]) Len() int64 {
+	return int64(r.tree.Size())
+}
@@ -0,0 +1,181 @@
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRangeMap(t *testing.T) {
+	_, err := NewRangeMap[int, string](nil)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+	rm, err := NewRangeMap[int, string](compare())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), rm.Len())
+}
+
+func TestRangeMap_Put(t *testing.T) {
+	type put struct {
+		lo, hi int
+		val    string
+	}
+	tests := []struct {
+		name    string
+		puts    []put
+		wantErr error
+		want    []RangeEntry[int, string]
+	}{
+		{
+			name:    "invalid range",
+			puts:    []put{{lo: 5, hi: 5, val: "a"}},
+			wantErr: errRangeMapInvalidRange,
+			want:    []RangeEntry[int, string]{},
+		},
+		{
+			name: "disjoint",
+			puts: []put{{0, 10, "a"}, {20, 30, "b"}},
+			want: []RangeEntry[int, string]{{0, 10, "a"}, {20, 30, "b"}},
+		},
+		{
+			name: "split",
+			puts: []put{{0, 30, "a"}, {10, 20, "b"}},
+			want: []RangeEntry[int, string]{{0, 10, "a"}, {10, 20, "b"}, {20, 30, "a"}},
+		},
+		{
+			name: "overwrite several",
+			puts: []put{{0, 10, "a"}, {10, 20, "b"}, {20, 30, "c"}, {5, 25, "d"}},
+			want: []RangeEntry[int, string]{{0, 5, "a"}, {5, 25, "d"}, {25, 30, "c"}},
+		},
+		{
+			name: "cover exactly",
+			puts: []put{{0, 10, "a"}, {0, 10, "b"}},
+			want: []RangeEntry[int, string]{{0, 10, "b"}},
+		},
+		{
+			name: "coalesce",
+			puts: []put{{0, 10, "a"}, {20, 30, "a"}, {10, 20, "a"}},
+			want: []RangeEntry[int, string]{{0, 30, "a"}},
+		},
+		{
+			name: "coalesce after split",
+			puts: []put{{0, 30, "a"}, {10, 20, "b"}, {10, 20, "a"}},
+			want: []RangeEntry[int, string]{{0, 30, "a"}},
+		},
+		{
+			name: "adjacent with different value",
+			puts: []put{{0, 10, "a"}, {10, 20, "b"}},
+			want: []RangeEntry[int, string]{{0, 10, "a"}, {10, 20, "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm, err := NewRangeMap[int, string](compare())
+			require.NoError(t, err)
+			for _, p := range tt.puts {
+				err = rm.Put(p.lo, p.hi, p.val)
+				if err != nil {
+					break
+				}
+			}
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, rm.Entries())
+			assert.Equal(t, int64(len(tt.want)), rm.Len())
+		})
+	}
+}
+
+func TestRangeMap_Get(t *testing.T) {
+	rm, err := NewRangeMap[int, string](compare())
+	require.NoError(t, err)
+	require.NoError(t, rm.Put(0, 10, "a"))
+	require.NoError(t, rm.Put(20, 30, "b"))
+	tests := []struct {
+		name    string
+		key     int
+		wantVal string
+		wantOk  bool
+	}{
+		{name: "before all", key: -1},
+		{name: "lower bound", key: 0, wantVal: "a", wantOk: true},
+		{name: "inside", key: 9, wantVal: "a", wantOk: true},
+		{name: "upper bound excluded", key: 10},
+		{name: "gap", key: 15},
+		{name: "second range", key: 25, wantVal: "b", wantOk: true},
+		{name: "after all", key: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, ok := rm.Get(tt.key)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantVal, val)
+		})
+	}
+	entry, ok := rm.GetEntry(25)
+	assert.True(t, ok)
+	assert.Equal(t, RangeEntry[int, string]{Lo: 20, Hi: 30, Value: "b"}, entry)
+}
+
+func TestRangeMap_Remove(t *testing.T) {
+	tests := []struct {
+		name    string
+		lo, hi  int
+		wantErr error
+		want    []RangeEntry[int, string]
+	}{
+		{
+			name:    "invalid range",
+			lo:      10,
+			hi:      0,
+			wantErr: errRangeMapInvalidRange,
+			want:    []RangeEntry[int, string]{{0, 10, "a"}, {20, 30, "b"}},
+		},
+		{
+			name: "gap",
+			lo:   10,
+			hi:   20,
+			want: []RangeEntry[int, string]{{0, 10, "a"}, {20, 30, "b"}},
+		},
+		{
+			name: "middle of range",
+			lo:   3,
+			hi:   5,
+			want: []RangeEntry[int, string]{{0, 3, "a"}, {5, 10, "a"}, {20, 30, "b"}},
+		},
+		{
+			name: "across ranges",
+			lo:   5,
+			hi:   25,
+			want: []RangeEntry[int, string]{{0, 5, "a"}, {25, 30, "b"}},
+		},
+		{
+			name: "all",
+			lo:   -10,
+			hi:   100,
+			want: []RangeEntry[int, string]{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm, err := NewRangeMap[int, string](compare())
+			require.NoError(t, err)
+			require.NoError(t, rm.Put(0, 10, "a"))
+			require.NoError(t, rm.Put(20, 30, "b"))
+			assert.Equal(t, tt.wantErr, rm.Remove(tt.lo, tt.hi))
+			assert.Equal(t, tt.want, rm.Entries())
+		})
+	}
+}
+
+func TestRangeMap_Span(t *testing.T) {
+	rm, err := NewRangeMap[int, string](compare())
+	require.NoError(t, err)
+	_, _, ok := rm.Span()
+	assert.False(t, ok)
+	require.NoError(t, rm.Put(20, 30, "b"))
+	require.NoError(t, rm.Put(0, 10, "a"))
+	lo, hi, ok := rm.Span()
+	assert.True(t, ok)
+	assert.Equal(t, 0, lo)
+	assert.Equal(t, 30, hi)
+}
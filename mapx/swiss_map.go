@@ -0,0 +1,301 @@
+package mapx
+
+import "math/bits"
+
+const (
+	swissGroupSize = 8
+	// swissEmpty 空的槽位
+	swissEmpty byte = 0b1000_0000
+	// swissDeleted 被删除的槽位，也就是墓碑
+	swissDeleted byte = 0b1111_1110
+	// 以下常量用于同时处理一个分组里面 8 个控制字节
+	swissLSB    uint64 = 0x0101_0101_0101_0101
+	swissMSB    uint64 = 0x8080_8080_8080_8080
+	swissEmpty8        = uint64(swissEmpty) * swissLSB
+	// swissMigrateStep 每次写操作迁移的分组数量
+	swissMigrateStep = 2
+)
+
+// swissGroup 是一个分组，包含 8 个槽位
+// ctrl 是 8 个控制字节：最高位为 1 表示空或者墓碑，否则低 7 位是哈希值的一部分
+type swissGroup[K Hashable, V any] struct {
+	ctrl uint64
+	keys [swissGroupSize]K
+	vals [swissGroupSize]V
+}
+
+func (g *swissGroup[K, V]) setCtrl(i int, c byte) {
+	shift := uint(i * 8)
+	g.ctrl = g.ctrl&^(0xff<<shift) | uint64(c)<<shift
+}
+
+// matchH2 返回控制字节可能等于 h2 的槽位，可能会有误报，所以还要比较键
+func (g *swissGroup[K, V]) matchH2(h2 byte) uint64 {
+	x := g.ctrl ^ (swissLSB * uint64(h2))
+	return (x - swissLSB) &^ x & swissMSB
+}
+
+// matchEmpty 返回空的槽位
+func (g *swissGroup[K, V]) matchEmpty() uint64 {
+	return g.ctrl &^ (g.ctrl << 6) & swissMSB
+}
+
+// matchEmptyOrDeleted 返回空的槽位或者墓碑
+func (g *swissGroup[K, V]) matchEmptyOrDeleted() uint64 {
+	return g.ctrl & swissMSB
+}
+
+// matchFull 返回存放了键值对的槽位
+func (g *swissGroup[K, V]) matchFull() uint64 {
+	return ^g.ctrl & swissMSB
+}
+
+// firstSlot 返回匹配结果中第一个槽位的下标，并且清除它
+func firstSlot(match *uint64) int {
+	i := bits.TrailingZeros64(*match) / 8
+	*match &= *match - 1
+	return i
+}
+
+type swissTable[K Hashable, V any] struct {
+	groups []swissGroup[K, V]
+	mask   uint64
+	// used 是存放了键值对的槽位以及墓碑的数量
+	used int
+	// limit 是 used 的上限，超过之后就需要扩容，负载因子为 7/8
+	limit int
+}
+
+func newSwissTable[K Hashable, V any](groups int) *swissTable[K, V] {
+	t := &swissTable[K, V]{
+		groups: make([]swissGroup[K, V], groups),
+		mask:   uint64(groups - 1),
+		limit:  groups * swissGroupSize * 7 / 8,
+	}
+	for i := range t.groups {
+		t.groups[i].ctrl = swissEmpty8
+	}
+	return t
+}
+
+// find 返回 key 所在的分组和槽位
+func (t *swissTable[K, V]) find(key K, h1 uint64, h2 byte) (*swissGroup[K, V], int, bool) {
+	pos := h1 & t.mask
+	// 三角数探测在分组数量为 2 的幂的时候，最多 len(groups) 步就可以遍历所有分组
+	for step := uint64(1); step <= t.mask+1; step++ {
+		g := &t.groups[pos]
+		match := g.matchH2(h2)
+		for match != 0 {
+			i := firstSlot(&match)
+			if g.keys[i].Equals(key) {
+				return g, i, true
+			}
+		}
+		if g.matchEmpty() != 0 {
+			break
+		}
+		pos = (pos + step) & t.mask
+	}
+	return nil, 0, false
+}
+
+// insert 将一个确定不存在的键插入到探测序列中第一个空槽位或者墓碑上
+func (t *swissTable[K, V]) insert(key K, val V, h1 uint64, h2 byte) {
+	pos := h1 & t.mask
+	for step := uint64(1); ; step++ {
+		g := &t.groups[pos]
+		if match := g.matchEmptyOrDeleted(); match != 0 {
+			i := firstSlot(&match)
+			if byte(g.ctrl>>(i*8)) == swissEmpty {
+				t.used++
+			}
+			g.setCtrl(i, h2)
+			g.keys[i], g.vals[i] = key, val
+			return
+		}
+		pos = (pos + step) & t.mask
+	}
+}
+
+// remove 删除分组中的某一个槽位
+// 如果分组中还有空槽位，说明探测序列不会越过这个分组，可以直接置空，否则只能留下墓碑
+func (t *swissTable[K, V]) remove(g *swissGroup[K, V], i int) {
+	var k K
+	var v V
+	g.keys[i], g.vals[i] = k, v
+	if g.matchEmpty() != 0 {
+		g.setCtrl(i, swissEmpty)
+		t.used--
+		return
+	}
+	g.setCtrl(i, swissDeleted)
+}
+
+// SwissMap 是基于开放寻址法的 Map，参考了 SwissTable 的设计
+// 槽位按照 8 个一组，每个槽位有一个控制字节，查找的时候一次比较一个分组的控制字节
+// 扩容是渐进式的：扩容之后旧表依旧保留，每次写操作迁移一部分，避免一次扩容带来的长时间停顿
+// 和 HashMap 相比，它不需要为每个键值对分配节点，也只需要计算一次哈希值
+type SwissMap[K Hashable, V any] struct {
+	cur *swissTable[K, V]
+	// old 是扩容过程中的旧表，迁移完成之后为 nil
+	old *swissTable[K, V]
+	// migrated 是旧表中已经迁移完成的分组数量
+	migrated int
+	length   int64
+}
+
+// NewSwissMap 创建一个 SwissMap，size 是预计的键值对数量
+func NewSwissMap[K Hashable, V any](size int) *SwissMap[K, V] {
+	groups := 1
+	for groups*swissGroupSize*7/8 < size {
+		groups <<= 1
+	}
+	return &SwissMap[K, V]{cur: newSwissTable[K, V](groups)}
+}
+
+// mixHash 打散整数的比特位，避免连续的整数都落在同一个分支
+// 算法来自 splitmix64
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func swissHash[K Hashable](key K) (uint64, byte) {
+	h := mixHash(key.Code())
+	return h >> 7, byte(h & 0x7f)
+}
+
+func (m *SwissMap[K, V]) Put(key K, val V) error {
+	h1, h2 := swissHash(key)
+	m.migrate()
+	if g, i, ok := m.cur.find(key, h1, h2); ok {
+		g.vals[i] = val
+		return nil
+	}
+	if m.old != nil {
+		// 键还在旧表中，挪到新表
+		m.delete(m.old, key, h1, h2)
+	}
+	if m.cur.used >= m.cur.limit {
+		m.grow()
+	}
+	m.cur.insert(key, val, h1, h2)
+	m.length++
+	return nil
+}
+
+func (m *SwissMap[K, V]) Get(key K) (V, bool) {
+	h1, h2 := swissHash(key)
+	if g, i, ok := m.cur.find(key, h1, h2); ok {
+		return g.vals[i], true
+	}
+	if m.old != nil {
+		if g, i, ok := m.old.find(key, h1, h2); ok {
+			return g.vals[i], true
+		}
+	}
+	var v V
+	return v, false
+}
+
+func (m *SwissMap[K, V]) Delete(key K) (V, bool) {
+	h1, h2 := swissHash(key)
+	m.migrate()
+	if val, ok := m.delete(m.cur, key, h1, h2); ok {
+		return val, true
+	}
+	if m.old != nil {
+		return m.delete(m.old, key, h1, h2)
+	}
+	var v V
+	return v, false
+}
+
+func (m *SwissMap[K, V]) delete(t *swissTable[K, V], key K, h1 uint64, h2 byte) (V, bool) {
+	g, i, ok := t.find(key, h1, h2)
+	if !ok {
+		var v V
+		return v, false
+	}
+	val := g.vals[i]
+	t.remove(g, i)
+	m.length--
+	return val, true
+}
+
+// Keys 返回所有的键，顺序是不确定的
+func (m *SwissMap[K, V]) Keys() []K {
+	res := make([]K, 0, m.length)
+	m.each(func(g *swissGroup[K, V], i int) {
+		res = append(res, g.keys[i])
+	})
+	return res
+}
+
+// Values 返回所有的值，顺序和 Keys 一致
+func (m *SwissMap[K, V]) Values() []V {
+	res := make([]V, 0, m.length)
+	m.each(func(g *swissGroup[K, V], i int) {
+		res = append(res, g.vals[i])
+	})
+	return res
+}
+
+func (m *SwissMap[K, V]) Len() int64 {
+	return m.length
+}
+
+func (m *SwissMap[K, V]) each(fn func(g *swissGroup[K, V], i int)) {
+	for _, t := range []*swissTable[K, V]{m.cur, m.old} {
+		if t == nil {
+			continue
+		}
+		for gi := range t.groups {
+			g := &t.groups[gi]
+			match := g.matchFull()
+			for match != 0 {
+				fn(g, firstSlot(&match))
+			}
+		}
+	}
+}
+
+// grow 创建新表并开始迁移
+// 如果大部分槽位被墓碑占据，那么新表的大小不变，相当于清理墓碑
+func (m *SwissMap[K, V]) grow() {
+	// 上一次扩容还没迁移完，先全部迁移掉
+	for m.old != nil {
+		m.migrate()
+	}
+	groups := len(m.cur.groups)
+	if int(m.length) >= m.cur.limit/2 {
+		groups <<= 1
+	}
+	m.old, m.cur, m.migrated = m.cur, newSwissTable[K, V](groups), 0
+}
+
+// migrate 从旧表迁移 swissMigrateStep 个分组到新表
+func (m *SwissMap[K, V]) migrate() {
+	if m.old == nil {
+		return
+	}
+	for n := 0; n < swissMigrateStep && m.migrated < len(m.old.groups); n++ {
+		g := &m.old.groups[m.migrated]
+		match := g.matchFull()
+		for match != 0 {
+			i := firstSlot(&match)
+			h1, h2 := swissHash(g.keys[i])
+			m.cur.insert(g.keys[i], g.vals[i], h1, h2)
+		}
+		// 迁移过的分组全部置为墓碑，这样旧表里面其它键的探测序列不会被打断
+		*g = swissGroup[K, V]{ctrl: uint64(swissDeleted) * swissLSB}
+		m.migrated++
+	}
+	if m.migrated == len(m.old.groups) {
+		m.old = nil
+	}
+}
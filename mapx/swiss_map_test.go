@@ -0,0 +1,204 @@
+package mapx
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 借助 testData 来验证一下 SwissMap 实现了 mapi 接口
+var _ mapi[testData, int] = &SwissMap[testData, int]{}
+
+func TestSwissGroup_Match(t *testing.T) {
+	g := &swissGroup[hashInt, int]{ctrl: swissEmpty8}
+	assert.Equal(t, swissMSB, g.matchEmpty())
+	assert.Equal(t, swissMSB, g.matchEmptyOrDeleted())
+	assert.Equal(t, uint64(0), g.matchFull())
+
+	g.setCtrl(0, 0x12)
+	g.setCtrl(3, swissDeleted)
+	g.setCtrl(5, 0x12)
+	g.setCtrl(7, 0x34)
+	match := g.matchH2(0x12)
+	assert.Equal(t, 0, firstSlot(&match))
+	assert.Equal(t, 5, firstSlot(&match))
+	assert.Equal(t, uint64(0), match)
+
+	match = g.matchFull()
+	assert.Equal(t, 0, firstSlot(&match))
+	assert.Equal(t, 5, firstSlot(&match))
+	assert.Equal(t, 7, firstSlot(&match))
+	assert.Equal(t, uint64(0), match)
+
+	match = g.matchEmpty()
+	for _, want := range []int{1, 2, 4, 6} {
+		assert.Equal(t, want, firstSlot(&match))
+	}
+	assert.Equal(t, uint64(0), match)
+}
+
+func TestSwissMap(t *testing.T) {
+	m := NewSwissMap[testData, int](0)
+	require.NoError(t, m.Put(newTestData(1), 1))
+	require.NoError(t, m.Put(newTestData(11), 11))
+	require.NoError(t, m.Put(newTestData(2), 2))
+	require.NoError(t, m.Put(newTestData(1), 101))
+	assert.Equal(t, int64(3), m.Len())
+
+	val, ok := m.Get(newTestData(1))
+	assert.True(t, ok)
+	assert.Equal(t, 101, val)
+	_, ok = m.Get(newTestData(21))
+	assert.False(t, ok)
+
+	val, ok = m.Delete(newTestData(11))
+	assert.True(t, ok)
+	assert.Equal(t, 11, val)
+	_, ok = m.Delete(newTestData(11))
+	assert.False(t, ok)
+
+	assert.ElementsMatch(t, []testData{newTestData(1), newTestData(2)}, m.Keys())
+	assert.ElementsMatch(t, []int{101, 2}, m.Values())
+	assert.Equal(t, int64(2), m.Len())
+}
+
+// 随机增删，与内置 map 的结果对比，覆盖扩容、渐进式迁移以及墓碑
+func TestSwissMap_Random(t *testing.T) {
+	testCases := []struct {
+		name  string
+		size  int
+		keys  int
+		times int
+	}{
+		{name: "grow from empty", size: 0, keys: 5000, times: 50000},
+		{name: "pre sized", size: 5000, keys: 5000, times: 50000},
+		{name: "few keys many deletes", size: 0, keys: 50, times: 50000},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			m := NewSwissMap[hashInt, int](tc.size)
+			want := make(map[hashInt]int)
+			migrating := false
+			for i := 0; i < tc.times; i++ {
+				k := newHashInt(r.Intn(tc.keys))
+				switch r.Intn(3) {
+				case 0:
+					val, ok := m.Delete(k)
+					wantVal, wantOk := want[k]
+					assert.Equal(t, wantOk, ok)
+					assert.Equal(t, wantVal, val)
+					delete(want, k)
+				case 1:
+					val, ok := m.Get(k)
+					wantVal, wantOk := want[k]
+					assert.Equal(t, wantOk, ok)
+					assert.Equal(t, wantVal, val)
+				default:
+					require.NoError(t, m.Put(k, i))
+					want[k] = i
+				}
+				migrating = migrating || m.old != nil
+				if i%1000 == 0 {
+					assert.Equal(t, int64(len(want)), m.Len())
+					assert.Equal(t, len(want), len(m.Keys()))
+				}
+			}
+			assert.Equal(t, int64(len(want)), m.Len())
+			assert.ElementsMatch(t, Keys(want), m.Keys())
+			if tc.size == 0 {
+				assert.True(t, migrating)
+			}
+		})
+	}
+}
+
+func TestSwissMap_HashCollision(t *testing.T) {
+	// testData 只有 10 种哈希值
+	m := NewSwissMap[testData, int](0)
+	for i := 0; i < 300; i++ {
+		require.NoError(t, m.Put(newTestData(i), i))
+	}
+	for i := 0; i < 300; i += 3 {
+		_, ok := m.Delete(newTestData(i))
+		assert.True(t, ok)
+	}
+	assert.Equal(t, int64(200), m.Len())
+	for i := 0; i < 300; i++ {
+		val, ok := m.Get(newTestData(i))
+		assert.Equal(t, i%3 != 0, ok)
+		if ok {
+			assert.Equal(t, i, val)
+		}
+	}
+}
+
+// goos: linux
+// goarch: amd64
+// pkg: github.com/WeiXinao/xkit/mapx
+// cpu: Intel(R) Xeon(R) Processor
+// BenchmarkSwissMap/swissmap_put         	11479953	       106.5 ns/op	       8 B/op	       1 allocs/op
+// BenchmarkSwissMap/hashmap_put          	 7743008	       215.5 ns/op	       8 B/op	       1 allocs/op
+// BenchmarkSwissMap/map_put              	27815584	        40.12 ns/op	       0 B/op	       0 allocs/op
+// BenchmarkSwissMap/swissmap_get         	16991196	        91.64 ns/op	       8 B/op	       1 allocs/op
+// BenchmarkSwissMap/hashmap_get          	13769623	       107.0 ns/op	       8 B/op	       1 allocs/op
+// BenchmarkSwissMap/map_get              	41395454	        29.91 ns/op	       0 B/op	       0 allocs/op
+// SwissMap 剩下的一次内存分配来自 Hashable.Equals 的参数是 any
+
+func BenchmarkSwissMap(b *testing.B) {
+	const size = 1 << 16
+	keys := make([]hashInt, size)
+	for i := range keys {
+		keys[i] = newHashInt(rand.Int())
+	}
+	b.Run("swissmap_put", func(b *testing.B) {
+		m := NewSwissMap[hashInt, int](0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = m.Put(keys[i&(size-1)], i)
+		}
+	})
+	b.Run("hashmap_put", func(b *testing.B) {
+		m := NewHashMap[hashInt, int](0)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = m.Put(keys[i&(size-1)], i)
+		}
+	})
+	b.Run("map_put", func(b *testing.B) {
+		m := make(map[hashInt]int)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m[keys[i&(size-1)]] = i
+		}
+	})
+
+	swissMap := NewSwissMap[hashInt, int](size)
+	hashMap := NewHashMap[hashInt, int](size)
+	builtin := make(map[hashInt]int, size)
+	for i, k := range keys {
+		_ = swissMap.Put(k, i)
+		_ = hashMap.Put(k, i)
+		builtin[k] = i
+	}
+	b.Run("swissmap_get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = swissMap.Get(keys[i&(size-1)])
+		}
+	})
+	b.Run("hashmap_get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = hashMap.Get(keys[i&(size-1)])
+		}
+	})
+	b.Run("map_get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = builtin[keys[i&(size-1)]]
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package mapx
+
+import "github.com/WeiXinao/xkit"
+
+// Cell 是 Table 中的一个单元格
+type Cell[R any, C any, V any] struct {
+	RowKey    R
+	ColumnKey C
+	Value     V
+}
+
+// Table 是以行键和列键共同作为键的二维 Map
+// 内部同时维护了按行索引和按列索引的两份数据，因此按行和按列的查询、删除都是高效的
+type Table[R any, C any, V any] struct {
+	rows mapi[R, mapi[C, V]]
+	cols mapi[C, mapi[R, V]]
+	// newRow 创建一行，也就是以列键为键的 map
+	newRow func() mapi[C, V]
+	// newCol 创建一列，也就是以行键为键的 map
+	newCol func() mapi[R, V]
+	size   *int64
+}
+
+// NewBuiltinTable 创建一个基于内置 map 的 Table
+func NewBuiltinTable[R comparable, C comparable, V any]() *Table[R, C, V] {
+	return &Table[R, C, V]{
+		rows: newBuiltinMap[R, mapi[C, V]](0),
+		cols: newBuiltinMap[C, mapi[R, V]](0),
+		newRow: func() mapi[C, V] {
+			return newBuiltinMap[C, V](0)
+		},
+		newCol: func() mapi[R, V] {
+			return newBuiltinMap[R, V](0)
+		},
+		size: new(int64),
+	}
+}
+
+// NewTreeTable 创建一个基于 TreeMap 的 Table
+// RowKeys 和 ColumnKeys 以及行列视图的键都是有序的
+// 注意：
+// - rowCompare 和 colCompare 都不能为 nil
+func NewTreeTable[R any, C any, V any](rowCompare xkit.Comparator[R],
+	colCompare xkit.Comparator[C]) (*Table[R, C, V], error) {
+	rows, err := NewTreeMap[R, mapi[C, V]](rowCompare)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := NewTreeMap[C, mapi[R, V]](colCompare)
+	if err != nil {
+		return nil, err
+	}
+	return &Table[R, C, V]{
+		rows: rows,
+		cols: cols,
+		newRow: func() mapi[C, V] {
+			m, _ := NewTreeMap[C, V](colCompare)
+			return m
+		},
+		newCol: func() mapi[R, V] {
+			m, _ := NewTreeMap[R, V](rowCompare)
+			return m
+		},
+		size: new(int64),
+	}, nil
+}
+
+// Put 设置 (r, c) 单元格的值，如果已经存在，那么原值会被替换
+func (t *Table[R, C, V]) Put(r R, c C, val V) error {
+	row, ok := t.rows.Get(r)
+	if !ok {
+		row = t.newRow()
+		if err := t.rows.Put(r, row); err != nil {
+			return err
+		}
+	}
+	col, ok := t.cols.Get(c)
+	if !ok {
+		col = t.newCol()
+		if err := t.cols.Put(c, col); err != nil {
+			return err
+		}
+	}
+	_, exist := row.Get(c)
+	if err := row.Put(c, val); err != nil {
+		return err
+	}
+	if err := col.Put(r, val); err != nil {
+		return err
+	}
+	if !exist {
+		*t.size++
+	}
+	return nil
+}
+
+// Get 返回 (r, c) 单元格的值
+func (t *Table[R, C, V]) Get(r R, c C) (V, bool) {
+	if row, ok := t.rows.Get(r); ok {
+		return row.Get(c)
+	}
+	var v V
+	return v, false
+}
+
+// Contains 判断 (r, c) 单元格是否存在
+func (t *Table[R, C, V]) Contains(r R, c C) bool {
+	_, ok := t.Get(r, c)
+	return ok
+}
+
+// Delete 删除 (r, c) 单元格，返回被删除的值
+func (t *Table[R, C, V]) Delete(r R, c C) (V, bool) {
+	row, ok := t.rows.Get(r)
+	if !ok {
+		var v V
+		return v, false
+	}
+	val, ok := row.Delete(c)
+	if !ok {
+		return val, false
+	}
+	if row.Len() == 0 {
+		t.rows.Delete(r)
+	}
+	if col, ok := t.cols.Get(c); ok {
+		col.Delete(r)
+		if col.Len() == 0 {
+			t.cols.Delete(c)
+		}
+	}
+	*t.size--
+	return val, true
+}
+
+// DeleteRow 删除整行，返回被删除的单元格数量
+func (t *Table[R, C, V]) DeleteRow(r R) int {
+	row, ok := t.rows.Delete(r)
+	if !ok {
+		return 0
+	}
+	for _, c := range row.Keys() {
+		if col, ok := t.cols.Get(c); ok {
+			col.Delete(r)
+			if col.Len() == 0 {
+				t.cols.Delete(c)
+			}
+		}
+	}
+	*t.size -= row.Len()
+	return int(row.Len())
+}
+
+// DeleteColumn 删除整列，返回被删除的单元格数量
+func (t *Table[R, C, V]) DeleteColumn(c C) int {
+	return t.Transpose().DeleteRow(c)
+}
+
+// Row 返回 r 这一行的视图，键是列键
+// 视图是实时的，对视图的修改会直接作用在 Table 上
+func (t *Table[R, C, V]) Row(r R) *TableView[C, V] {
+	return &TableView[C, V]{
+		axis: func() (mapi[C, V], bool) {
+			return t.rows.Get(r)
+		},
+		put: func(c C, val V) error {
+			return t.Put(r, c, val)
+		},
+		del: func(c C) (V, bool) {
+			return t.Delete(r, c)
+		},
+	}
+}
+
+// Column 返回 c 这一列的视图，键是行键
+// 视图是实时的，对视图的修改会直接作用在 Table 上
+func (t *Table[R, C, V]) Column(c C) *TableView[R, V] {
+	return t.Transpose().Row(c)
+}
+
+// RowKeys 返回所有至少有一个单元格的行键
+func (t *Table[R, C, V]) RowKeys() []R {
+	return t.rows.Keys()
+}
+
+// ColumnKeys 返回所有至少有一个单元格的列键
+func (t *Table[R, C, V]) ColumnKeys() []C {
+	return t.cols.Keys()
+}
+
+// CellSet 按行返回所有的单元格
+func (t *Table[R, C, V]) CellSet() []Cell[R, C, V] {
+	res := make([]Cell[R, C, V], 0, *t.size)
+	// 内置 map 的 Keys 和 Values 顺序不一致，所以这里只能通过键来取值
+	for _, r := range t.rows.Keys() {
+		row, _ := t.rows.Get(r)
+		for _, c := range row.Keys() {
+			val, _ := row.Get(c)
+			res = append(res, Cell[R, C, V]{RowKey: r, ColumnKey: c, Value: val})
+		}
+	}
+	return res
+}
+
+// Transpose 返回行列互换之后的 Table
+// 返回的 Table 和原本的 Table 共享数据，对其中一个的修改会反映到另外一个上
+func (t *Table[R, C, V]) Transpose() *Table[C, R, V] {
+	return &Table[C, R, V]{
+		rows:   t.cols,
+		cols:   t.rows,
+		newRow: t.newCol,
+		newCol: t.newRow,
+		size:   t.size,
+	}
+}
+
+// Len 返回单元格的数量
+func (t *Table[R, C, V]) Len() int64 {
+	return *t.size
+}
+
+// TableView 是 Table 中某一行或者某一列的视图
+type TableView[K any, V any] struct {
+	axis func() (mapi[K, V], bool)
+	put  func(key K, val V) error
+	del  func(key K) (V, bool)
+}
+
+// Put 设置视图中 key 对应的值
+func (v *TableView[K, V]) Put(key K, val V) error {
+	return v.put(key, val)
+}
+
+// Get 返回视图中 key 对应的值
+func (v *TableView[K, V]) Get(key K) (V, bool) {
+	if m, ok := v.axis(); ok {
+		return m.Get(key)
+	}
+	var val V
+	return val, false
+}
+
+// Delete 删除视图中的 key
+func (v *TableView[K, V]) Delete(key K) (V, bool) {
+	return v.del(key)
+}
+
+// Keys 返回视图中所有的键
+func (v *TableView[K, V]) Keys() []K {
+	if m, ok := v.axis(); ok {
+		return m.Keys()
+	}
+	return []K{}
+}
+
+// Values 返回视图中所有的值
+func (v *TableView[K, V]) Values() []V {
+	if m, ok := v.axis(); ok {
+		return m.Values()
+	}
+	return []V{}
+}
+
+// Len 返回视图中键值对的数量
+func (v *TableView[K, V]) Len() int64 {
+	if m, ok := v.axis(); ok {
+		return m.Len()
+	}
+	return 0
+}
@@ -0,0 +1,139 @@
+package mapx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 借助 TableView 来验证一下它实现了 mapi 接口
+var _ mapi[int, int] = &TableView[int, int]{}
+
+func newTestTreeTable(t *testing.T) *Table[int, int, string] {
+	table, err := NewTreeTable[int, int, string](compare(), compare())
+	require.NoError(t, err)
+	return table
+}
+
+func TestNewTreeTable(t *testing.T) {
+	_, err := NewTreeTable[int, int, string](nil, compare())
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+	_, err = NewTreeTable[int, int, string](compare(), nil)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+}
+
+func TestTable_PutGetDelete(t *testing.T) {
+	tables := map[string]*Table[int, int, string]{
+		"builtin": NewBuiltinTable[int, int, string](),
+		"tree":    newTestTreeTable(t),
+	}
+	for name, table := range tables {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, table.Put(1, 1, "a"))
+			require.NoError(t, table.Put(1, 2, "b"))
+			require.NoError(t, table.Put(2, 1, "c"))
+			require.NoError(t, table.Put(1, 1, "d"))
+			assert.Equal(t, int64(3), table.Len())
+
+			val, ok := table.Get(1, 1)
+			assert.True(t, ok)
+			assert.Equal(t, "d", val)
+			assert.False(t, table.Contains(2, 2))
+			assert.False(t, table.Contains(3, 1))
+
+			val, ok = table.Delete(2, 1)
+			assert.True(t, ok)
+			assert.Equal(t, "c", val)
+			_, ok = table.Delete(2, 1)
+			assert.False(t, ok)
+			_, ok = table.Delete(1, 3)
+			assert.False(t, ok)
+			assert.Equal(t, int64(2), table.Len())
+			// 空行会被移除
+			assert.ElementsMatch(t, []int{1}, table.RowKeys())
+			assert.ElementsMatch(t, []int{1, 2}, table.ColumnKeys())
+		})
+	}
+}
+
+func TestTable_RowColumn(t *testing.T) {
+	table := newTestTreeTable(t)
+	require.NoError(t, table.Put(1, 1, "a"))
+	require.NoError(t, table.Put(1, 2, "b"))
+	require.NoError(t, table.Put(2, 1, "c"))
+
+	row := table.Row(1)
+	assert.Equal(t, []int{1, 2}, row.Keys())
+	assert.Equal(t, []string{"a", "b"}, row.Values())
+	assert.Equal(t, int64(2), row.Len())
+
+	col := table.Column(1)
+	assert.Equal(t, []int{1, 2}, col.Keys())
+	assert.Equal(t, []string{"a", "c"}, col.Values())
+
+	// 视图的修改会反映到 Table 上
+	require.NoError(t, row.Put(3, "d"))
+	val, ok := table.Get(1, 3)
+	assert.True(t, ok)
+	assert.Equal(t, "d", val)
+	val, ok = col.Delete(2)
+	assert.True(t, ok)
+	assert.Equal(t, "c", val)
+	assert.False(t, table.Contains(2, 1))
+	assert.Equal(t, int64(3), table.Len())
+
+	// 不存在的行
+	empty := table.Row(4)
+	assert.Equal(t, []int{}, empty.Keys())
+	assert.Equal(t, []string{}, empty.Values())
+	assert.Equal(t, int64(0), empty.Len())
+	_, ok = empty.Get(1)
+	assert.False(t, ok)
+	require.NoError(t, empty.Put(1, "e"))
+	assert.Equal(t, int64(1), empty.Len())
+}
+
+func TestTable_DeleteRowColumn(t *testing.T) {
+	table := NewBuiltinTable[string, string, int]()
+	require.NoError(t, table.Put("r1", "c1", 1))
+	require.NoError(t, table.Put("r1", "c2", 2))
+	require.NoError(t, table.Put("r2", "c1", 3))
+	require.NoError(t, table.Put("r2", "c3", 4))
+
+	assert.Equal(t, 2, table.DeleteColumn("c1"))
+	assert.Equal(t, 0, table.DeleteColumn("c1"))
+	assert.Equal(t, int64(2), table.Len())
+	assert.ElementsMatch(t, []string{"c2", "c3"}, table.ColumnKeys())
+
+	assert.Equal(t, 1, table.DeleteRow("r1"))
+	assert.Equal(t, int64(1), table.Len())
+	assert.ElementsMatch(t, []string{"r2"}, table.RowKeys())
+	assert.ElementsMatch(t, []string{"c3"}, table.ColumnKeys())
+}
+
+func TestTable_CellSetTranspose(t *testing.T) {
+	table := newTestTreeTable(t)
+	require.NoError(t, table.Put(2, 1, "c"))
+	require.NoError(t, table.Put(1, 2, "b"))
+	require.NoError(t, table.Put(1, 1, "a"))
+	assert.Equal(t, []Cell[int, int, string]{
+		{RowKey: 1, ColumnKey: 1, Value: "a"},
+		{RowKey: 1, ColumnKey: 2, Value: "b"},
+		{RowKey: 2, ColumnKey: 1, Value: "c"},
+	}, table.CellSet())
+
+	transposed := table.Transpose()
+	assert.Equal(t, []Cell[int, int, string]{
+		{RowKey: 1, ColumnKey: 1, Value: "a"},
+		{RowKey: 1, ColumnKey: 2, Value: "c"},
+		{RowKey: 2, ColumnKey: 1, Value: "b"},
+	}, transposed.CellSet())
+
+	// 转置之后的 Table 与原本的 Table 共享数据
+	require.NoError(t, transposed.Put(3, 1, "d"))
+	val, ok := table.Get(1, 3)
+	assert.True(t, ok)
+	assert.Equal(t, "d", val)
+	assert.Equal(t, int64(4), table.Len())
+}
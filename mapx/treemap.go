@@ -6,7 +6,11 @@ import (
 	"github.com/WeiXinao/xkit/internal/tree"
 )
 
-var errTreeMapComparatorIsNull = errors.New("xkit: Comparator不能为nil")
+var (
+	errTreeMapComparatorIsNull = errors.New("xkit: Comparator不能为nil")
+	errTreeMapKeysNotSorted    = errors.New("xkit: TreeMap 的键必须严格递增")
+	errTreeMapLengthNotMatch   = errors.New("xkit: TreeMap 的键和值的数量必须相同")
+)
 
 // TreeMap 是基于红黑树实现的 Map
 type TreeMap[K any, V any] struct {
@@ -35,6 +39,26 @@ func NewTreeMap[K any, V any](compare xkit.Comparator[K]) (*TreeMap[K, V], error
 	}, nil
 }
 
+// NewTreeMapFromSorted 根据严格递增的键直接构造 TreeMap，时间复杂度是 O(n)
+// vals 和 keys 一一对应，vals 为 nil 的时候所有的值都是零值
+// 键没有严格递增或者键和值的数量不同的时候返回错误
+func NewTreeMapFromSorted[K any, V any](compare xkit.Comparator[K], keys []K, vals []V) (*TreeMap[K, V], error) {
+	if compare == nil {
+		return nil, errTreeMapComparatorIsNull
+	}
+	if vals != nil && len(vals) != len(keys) {
+		return nil, errTreeMapLengthNotMatch
+	}
+	for i := 1; i < len(keys); i++ {
+		if compare(keys[i-1], keys[i]) >= 0 {
+			return nil, errTreeMapKeysNotSorted
+		}
+	}
+	return &TreeMap[K, V]{
+		tree: tree.NewRBTreeFromSorted[K, V](compare, keys, vals),
+	}, nil
+}
+
 // putAll 将 map 传入 TreeMap
 // 需注意如果 map 中的 key 已存在，value 将被替换
 func putAll[K comparable, V any](treeMap *TreeMap[K, V], m map[K]V) {
@@ -83,3 +107,33 @@ func (t *TreeMap[K, V]) Values() []V {
 func (t *TreeMap[K, V]) Len() int64 {
 	return int64(t.tree.Size())
 }
+
+// Floor 返回小于等于 key 的最大键及其对应的值
+// 不存在这样的键时返回 false
+func (t *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	k, v, err := t.tree.Floor(key)
+	return k, v, err == nil
+}
+
+// Ceiling 返回大于等于 key 的最小键及其对应的值
+// 不存在这样的键时返回 false
+func (t *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	k, v, err := t.tree.Ceiling(key)
+	return k, v, err == nil
+}
+
+// Rank 返回小于 key 的键的数量，key 本身不需要存在
+func (t *TreeMap[K, V]) Rank(key K) int {
+	return t.tree.Rank(key)
+}
+
+// Select 返回按照键从小到大排序之后下标为 index 的键值对，下标从 0 开始
+// 下标超出范围会返回错误
+func (t *TreeMap[K, V]) Select(index int) (K, V, error) {
+	return t.tree.Select(index)
+}
+
+// CountRange 返回落在 [lo, hi) 中的键的数量
+func (t *TreeMap[K, V]) CountRange(lo, hi K) int {
+	return t.tree.CountRange(lo, hi)
+}
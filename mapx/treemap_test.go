@@ -90,6 +90,82 @@ func TestNewTreeMapWithMap(t *testing.T) {
 	}
 }
 
+func TestNewTreeMapFromSorted(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparable xkit.Comparator[int]
+		keys       []int
+		vals       []int
+		wantVal    []int
+		wantErr    error
+	}{
+		{
+			name:       "comparator nil",
+			comparable: nil,
+			wantErr:    errTreeMapComparatorIsNull,
+		},
+		{
+			name:       "empty",
+			comparable: compare(),
+			wantVal:    []int{},
+		},
+		{
+			name:       "with vals",
+			comparable: compare(),
+			keys:       []int{1, 3, 5, 7},
+			vals:       []int{10, 30, 50, 70},
+			wantVal:    []int{10, 30, 50, 70},
+		},
+		{
+			name:       "nil vals",
+			comparable: compare(),
+			keys:       []int{1, 3, 5},
+			wantVal:    []int{0, 0, 0},
+		},
+		{
+			name:       "not sorted",
+			comparable: compare(),
+			keys:       []int{1, 5, 3},
+			wantErr:    errTreeMapKeysNotSorted,
+		},
+		{
+			name:       "duplicate keys",
+			comparable: compare(),
+			keys:       []int{1, 3, 3},
+			wantErr:    errTreeMapKeysNotSorted,
+		},
+		{
+			name:       "length not match",
+			comparable: compare(),
+			keys:       []int{1, 3},
+			vals:       []int{1},
+			wantErr:    errTreeMapLengthNotMatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			treeMap, err := NewTreeMapFromSorted[int, int](tt.comparable, tt.keys, tt.vals)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.keys)), treeMap.Len())
+			assert.Equal(t, tt.wantVal, append([]int{}, treeMap.Values()...))
+			for i, k := range tt.keys {
+				val, ok := treeMap.Get(k)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantVal[i], val)
+			}
+			// 构造出来的 TreeMap 可以继续正常写入
+			require.NoError(t, treeMap.Put(4, 40))
+			val, ok := treeMap.Get(4)
+			assert.True(t, ok)
+			assert.Equal(t, 40, val)
+		})
+	}
+}
+
 func TestTreeMap_Get(t *testing.T) {
 	var tests = []struct {
 		name     string
@@ -483,3 +559,48 @@ func BenchmarkTreeMap(b *testing.B) {
 	})
 
 }
+
+func TestTreeMap_FloorCeiling(t *testing.T) {
+	treeMap, _ := NewTreeMap[int, int](compare())
+	putAll(treeMap, map[int]int{10: 1, 20: 2, 30: 3})
+	tests := []struct {
+		name        string
+		key         int
+		wantFloor   int
+		floorOk     bool
+		wantCeiling int
+		ceilingOk   bool
+	}{
+		{name: "less than min", key: 5, wantCeiling: 10, ceilingOk: true},
+		{name: "equal", key: 20, wantFloor: 20, floorOk: true, wantCeiling: 20, ceilingOk: true},
+		{name: "between", key: 25, wantFloor: 20, floorOk: true, wantCeiling: 30, ceilingOk: true},
+		{name: "greater than max", key: 35, wantFloor: 30, floorOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, _, ok := treeMap.Floor(tt.key)
+			assert.Equal(t, tt.floorOk, ok)
+			assert.Equal(t, tt.wantFloor, k)
+			k, _, ok = treeMap.Ceiling(tt.key)
+			assert.Equal(t, tt.ceilingOk, ok)
+			assert.Equal(t, tt.wantCeiling, k)
+		})
+	}
+}
+
+func TestTreeMap_RankSelect(t *testing.T) {
+	treeMap, _ := NewTreeMap[int, int](compare())
+	putAll(treeMap, map[int]int{10: 1, 20: 2, 30: 3, 40: 4})
+	assert.Equal(t, 0, treeMap.Rank(5))
+	assert.Equal(t, 2, treeMap.Rank(30))
+	assert.Equal(t, 3, treeMap.Rank(35))
+	k, v, err := treeMap.Select(1)
+	require.NoError(t, err)
+	assert.Equal(t, 20, k)
+	assert.Equal(t, 2, v)
+	_, _, err = treeMap.Select(4)
+	assert.Error(t, err)
+	assert.Equal(t, 2, treeMap.CountRange(15, 35))
+	_, _ = treeMap.Delete(20)
+	assert.Equal(t, 1, treeMap.CountRange(15, 35))
+}
@@ -0,0 +1,434 @@
+package mapx
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/WeiXinao/xkit"
+)
+
+var (
+	errTxDone                = errors.New("xkit: 事务已经提交或者回滚")
+	errTxConflict            = errors.New("xkit: 事务冲突，事务开始之后键已经被其它事务修改")
+	errTxVersionNotCommitted = errors.New("xkit: 版本还没有提交")
+	errTxVersionPruned       = errors.New("xkit: 版本已经被清理")
+)
+
+// txVersion 是某个键在某个版本上的值
+type txVersion[V any] struct {
+	version uint64
+	val     V
+	deleted bool
+}
+
+// txRecord 是某个键的多个版本，按照版本从小到大排列
+type txRecord[V any] struct {
+	versions []txVersion[V]
+}
+
+// at 返回版本 version 能看到的值
+func (r *txRecord[V]) at(version uint64) (V, bool) {
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if v := r.versions[i]; v.version <= version {
+			return v.val, !v.deleted
+		}
+	}
+	var val V
+	return val, false
+}
+
+func (r *txRecord[V]) latest() txVersion[V] {
+	return r.versions[len(r.versions)-1]
+}
+
+// prune 清理掉所有活跃的快照都不会再看到的版本
+// 只保留 minActive 能看到的版本以及更新的版本
+func (r *txRecord[V]) prune(minActive uint64) {
+	i := len(r.versions) - 1
+	for i > 0 && r.versions[i].version > minActive {
+		i--
+	}
+	if i > 0 {
+		r.versions = append(r.versions[:0], r.versions[i:]...)
+	}
+}
+
+type txWrite[V any] struct {
+	val     V
+	deleted bool
+}
+
+// TxMap 是支持事务的 Map
+// 它采用了简单的多版本并发控制（MVCC）：每次提交都会产生一个新的版本，
+// 事务和快照读取的都是它们创建时的版本，因此读者之间、读者与写者之间互不阻塞
+// 事务提交的时候，如果它修改的键在事务开始之后已经被其它事务修改，那么提交会失败
+type TxMap[K any, V any] struct {
+	lock      sync.RWMutex
+	m         mapi[K, *txRecord[V]]
+	newWrites func() mapi[K, *txWrite[V]]
+	version   uint64
+	// pruned 之前的版本可能已经被清理掉了，它以及之后的版本都还能读到
+	pruned uint64
+	length int64
+	// active 记录了正在使用的版本以及使用者的数量
+	active map[uint64]int
+}
+
+// NewTxMap 创建一个基于内置 map 的 TxMap
+func NewTxMap[K comparable, V any]() *TxMap[K, V] {
+	return newTxMap[K, V](newBuiltinMap[K, *txRecord[V]](0), func() mapi[K, *txWrite[V]] {
+		return newBuiltinMap[K, *txWrite[V]](0)
+	})
+}
+
+// NewTxHashMap 创建一个基于 HashMap 的 TxMap
+func NewTxHashMap[K Hashable, V any](size int) *TxMap[K, V] {
+	return newTxMap[K, V](NewHashMap[K, *txRecord[V]](size), func() mapi[K, *txWrite[V]] {
+		return NewHashMap[K, *txWrite[V]](0)
+	})
+}
+
+// NewTxTreeMap 创建一个基于 TreeMap 的 TxMap
+// 注意：
+// - compare 不能为 nil
+func NewTxTreeMap[K any, V any](compare xkit.Comparator[K]) (*TxMap[K, V], error) {
+	m, err := NewTreeMap[K, *txRecord[V]](compare)
+	if err != nil {
+		return nil, err
+	}
+	return newTxMap[K, V](m, func() mapi[K, *txWrite[V]] {
+		writes, _ := NewTreeMap[K, *txWrite[V]](compare)
+		return writes
+	}), nil
+}
+
+func newTxMap[K any, V any](m mapi[K, *txRecord[V]], newWrites func() mapi[K, *txWrite[V]]) *TxMap[K, V] {
+	return &TxMap[K, V]{
+		m:         m,
+		newWrites: newWrites,
+		active:    make(map[uint64]int),
+	}
+}
+
+// Begin 开启一个事务
+// 事务中的修改在 Commit 之前对其它人都不可见
+func (t *TxMap[K, V]) Begin() *Tx[K, V] {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &Tx[K, V]{
+		m:       t,
+		version: t.acquire(),
+		writes:  t.newWrites(),
+	}
+}
+
+// Snapshot 返回当前版本的只读快照
+// 快照不会看到之后提交的修改，用完之后需要调用 Close 释放
+func (t *TxMap[K, V]) Snapshot() *TxSnapshot[K, V] {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &TxSnapshot[K, V]{
+		m:       t,
+		version: t.acquire(),
+	}
+}
+
+// SnapshotAt 返回版本 version 的只读快照，用完之后需要调用 Close 释放
+// 提交的时候只会保留正在被使用的最小版本之后的数据，没有快照或者事务在使用的旧版本会被清理掉，
+// 所以只有最新的版本，以及在它之后一直被某个快照或者事务使用的版本才能读到
+// 如果 version 还没有提交，或者已经被清理，那么会返回 error
+func (t *TxMap[K, V]) SnapshotAt(version uint64) (*TxSnapshot[K, V], error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if version > t.version {
+		return nil, errTxVersionNotCommitted
+	}
+	if version < t.pruned {
+		return nil, errTxVersionPruned
+	}
+	t.active[version]++
+	return &TxSnapshot[K, V]{
+		m:       t,
+		version: version,
+	}, nil
+}
+
+// Version 返回最新提交的版本
+func (t *TxMap[K, V]) Version() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.version
+}
+
+// Put 在一个只包含这一个修改的事务中设置 key 对应的值
+func (t *TxMap[K, V]) Put(key K, val V) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.commit(t.version, []K{key}, []*txWrite[V]{{val: val}})
+}
+
+// Get 返回最新版本中 key 对应的值
+func (t *TxMap[K, V]) Get(key K) (V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.get(t.version, key)
+}
+
+// Delete 在一个只包含这一个修改的事务中删除 key
+func (t *TxMap[K, V]) Delete(key K) (V, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	val, ok := t.get(t.version, key)
+	if !ok {
+		return val, false
+	}
+	_ = t.commit(t.version, []K{key}, []*txWrite[V]{{deleted: true}})
+	return val, true
+}
+
+// Keys 返回最新版本中所有的键
+func (t *TxMap[K, V]) Keys() []K {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	keys, _ := t.keyValues(t.version)
+	return keys
+}
+
+// Values 返回最新版本中所有的值
+func (t *TxMap[K, V]) Values() []V {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	_, vals := t.keyValues(t.version)
+	return vals
+}
+
+// Len 返回最新版本中键值对的数量
+func (t *TxMap[K, V]) Len() int64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.length
+}
+
+func (t *TxMap[K, V]) get(version uint64, key K) (V, bool) {
+	if rec, ok := t.m.Get(key); ok {
+		return rec.at(version)
+	}
+	var val V
+	return val, false
+}
+
+func (t *TxMap[K, V]) keyValues(version uint64) ([]K, []V) {
+	keys := make([]K, 0, t.length)
+	vals := make([]V, 0, t.length)
+	for _, key := range t.m.Keys() {
+		if val, ok := t.get(version, key); ok {
+			keys = append(keys, key)
+			vals = append(vals, val)
+		}
+	}
+	return keys, vals
+}
+
+// commit 将 writes 作为一个新的版本提交
+// 调用者需要持有写锁
+func (t *TxMap[K, V]) commit(start uint64, keys []K, writes []*txWrite[V]) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if rec, ok := t.m.Get(key); ok && rec.latest().version > start {
+			return errTxConflict
+		}
+	}
+	// 先创建所有缺少的记录，再追加新的版本，这样 Put 失败的时候不会留下提交了一半的修改
+	recs := make([]*txRecord[V], len(keys))
+	created := make([]K, 0, len(keys))
+	for i, key := range keys {
+		rec, ok := t.m.Get(key)
+		if !ok && !writes[i].deleted {
+			rec = &txRecord[V]{}
+			if err := t.m.Put(key, rec); err != nil {
+				for _, k := range created {
+					t.m.Delete(k)
+				}
+				return err
+			}
+			created = append(created, key)
+		}
+		recs[i] = rec
+	}
+	version := t.version + 1
+	minActive := t.minActive(version)
+	t.pruned = max(t.pruned, minActive)
+	for i, rec := range recs {
+		if rec == nil {
+			continue
+		}
+		existed := len(rec.versions) > 0 && !rec.latest().deleted
+		rec.versions = append(rec.versions, txVersion[V]{
+			version: version,
+			val:     writes[i].val,
+			deleted: writes[i].deleted,
+		})
+		rec.prune(minActive)
+		if existed && writes[i].deleted {
+			t.length--
+		} else if !existed && !writes[i].deleted {
+			t.length++
+		}
+		// 没有任何人能够看到这个键了
+		if writes[i].deleted && len(rec.versions) == 1 {
+			t.m.Delete(keys[i])
+		}
+	}
+	t.version = version
+	return nil
+}
+
+// acquire 标记当前版本正在被使用，调用者需要持有写锁
+func (t *TxMap[K, V]) acquire() uint64 {
+	t.active[t.version]++
+	return t.version
+}
+
+// release 释放对版本的使用，调用者需要持有写锁
+func (t *TxMap[K, V]) release(version uint64) {
+	t.active[version]--
+	if t.active[version] <= 0 {
+		delete(t.active, version)
+	}
+}
+
+// minActive 返回正在被使用的最小版本，没有的话返回 version
+func (t *TxMap[K, V]) minActive(version uint64) uint64 {
+	for v := range t.active {
+		if v < version {
+			version = v
+		}
+	}
+	return version
+}
+
+// Tx 是 TxMap 上的事务
+// 事务读取的是它开始时的版本，以及它自己的修改
+// Tx 不是并发安全的，一个事务只应该在一个 goroutine 中使用
+type Tx[K any, V any] struct {
+	m       *TxMap[K, V]
+	version uint64
+	writes  mapi[K, *txWrite[V]]
+	done    bool
+}
+
+// Put 在事务中设置 key 对应的值
+func (tx *Tx[K, V]) Put(key K, val V) error {
+	if tx.done {
+		return errTxDone
+	}
+	return tx.writes.Put(key, &txWrite[V]{val: val})
+}
+
+// Get 返回事务中 key 对应的值
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	if w, ok := tx.writes.Get(key); ok {
+		return w.val, !w.deleted
+	}
+	tx.m.lock.RLock()
+	defer tx.m.lock.RUnlock()
+	return tx.m.get(tx.version, key)
+}
+
+// Delete 在事务中删除 key
+func (tx *Tx[K, V]) Delete(key K) (V, bool) {
+	var v V
+	if tx.done {
+		return v, false
+	}
+	val, ok := tx.Get(key)
+	if !ok {
+		return v, false
+	}
+	_ = tx.writes.Put(key, &txWrite[V]{deleted: true})
+	return val, true
+}
+
+// Commit 原子地提交事务中所有的修改
+// 如果事务中修改过的键在事务开始之后被其它事务修改过，那么返回错误，所有的修改都不会生效
+func (tx *Tx[K, V]) Commit() error {
+	if tx.done {
+		return errTxDone
+	}
+	tx.done = true
+	keys := tx.writes.Keys()
+	writes := make([]*txWrite[V], 0, len(keys))
+	for _, key := range keys {
+		w, _ := tx.writes.Get(key)
+		writes = append(writes, w)
+	}
+	tx.m.lock.Lock()
+	defer tx.m.lock.Unlock()
+	tx.m.release(tx.version)
+	return tx.m.commit(tx.version, keys, writes)
+}
+
+// Rollback 放弃事务中所有的修改
+func (tx *Tx[K, V]) Rollback() error {
+	if tx.done {
+		return errTxDone
+	}
+	tx.done = true
+	tx.m.lock.Lock()
+	defer tx.m.lock.Unlock()
+	tx.m.release(tx.version)
+	return nil
+}
+
+// TxSnapshot 是 TxMap 在某一个版本上的只读视图
+// TxSnapshot 是并发安全的
+type TxSnapshot[K any, V any] struct {
+	m       *TxMap[K, V]
+	version uint64
+	once    sync.Once
+}
+
+// Version 返回快照的版本
+func (s *TxSnapshot[K, V]) Version() uint64 {
+	return s.version
+}
+
+// Get 返回快照中 key 对应的值
+func (s *TxSnapshot[K, V]) Get(key K) (V, bool) {
+	s.m.lock.RLock()
+	defer s.m.lock.RUnlock()
+	return s.m.get(s.version, key)
+}
+
+// Keys 返回快照中所有的键
+func (s *TxSnapshot[K, V]) Keys() []K {
+	s.m.lock.RLock()
+	defer s.m.lock.RUnlock()
+	keys, _ := s.m.keyValues(s.version)
+	return keys
+}
+
+// Values 返回快照中所有的值
+func (s *TxSnapshot[K, V]) Values() []V {
+	s.m.lock.RLock()
+	defer s.m.lock.RUnlock()
+	_, vals := s.m.keyValues(s.version)
+	return vals
+}
+
+// Len 返回快照中键值对的数量
+func (s *TxSnapshot[K, V]) Len() int64 {
+	return int64(len(s.Keys()))
+}
+
+// Close 释放快照，之后 TxMap 可以清理掉只有这个快照才能看到的旧版本
+// 关闭之后不应该继续使用快照
+func (s *TxSnapshot[K, V]) Close() {
+	s.once.Do(func() {
+		s.m.lock.Lock()
+		defer s.m.lock.Unlock()
+		s.m.release(s.version)
+	})
+}
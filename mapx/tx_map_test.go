@@ -0,0 +1,270 @@
+package mapx
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 借助 testData 来验证一下 TxMap 实现了 mapi 接口
+var _ mapi[testData, int] = &TxMap[testData, int]{}
+
+func TestNewTxTreeMap(t *testing.T) {
+	_, err := NewTxTreeMap[int, int](nil)
+	assert.Equal(t, errTreeMapComparatorIsNull, err)
+}
+
+func TestTxMap_Commit(t *testing.T) {
+	tree, err := NewTxTreeMap[int, int](compare())
+	require.NoError(t, err)
+	maps := map[string]*TxMap[int, int]{
+		"builtin": NewTxMap[int, int](),
+		"tree":    tree,
+	}
+	for name, m := range maps {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, m.Put(1, 1))
+			require.NoError(t, m.Put(2, 2))
+
+			tx := m.Begin()
+			require.NoError(t, tx.Put(3, 3))
+			require.NoError(t, tx.Put(1, 11))
+			val, ok := tx.Delete(2)
+			assert.True(t, ok)
+			assert.Equal(t, 2, val)
+
+			// 事务读到自己的修改
+			val, ok = tx.Get(1)
+			assert.True(t, ok)
+			assert.Equal(t, 11, val)
+			_, ok = tx.Get(2)
+			assert.False(t, ok)
+
+			// 提交之前其它人看不到
+			val, _ = m.Get(1)
+			assert.Equal(t, 1, val)
+			_, ok = m.Get(3)
+			assert.False(t, ok)
+
+			require.NoError(t, tx.Commit())
+			assert.Equal(t, errTxDone, tx.Commit())
+			assert.Equal(t, errTxDone, tx.Put(4, 4))
+
+			assert.ElementsMatch(t, []int{1, 3}, m.Keys())
+			assert.ElementsMatch(t, []int{11, 3}, m.Values())
+			assert.Equal(t, int64(2), m.Len())
+			assert.Equal(t, uint64(3), m.Version())
+		})
+	}
+}
+
+func TestTxMap_Rollback(t *testing.T) {
+	m := NewTxMap[string, int]()
+	require.NoError(t, m.Put("a", 1))
+	tx := m.Begin()
+	require.NoError(t, tx.Put("a", 2))
+	require.NoError(t, tx.Put("b", 2))
+	require.NoError(t, tx.Rollback())
+	assert.Equal(t, errTxDone, tx.Rollback())
+	_, ok := tx.Delete("a")
+	assert.False(t, ok)
+
+	val, _ := m.Get("a")
+	assert.Equal(t, 1, val)
+	assert.Equal(t, []string{"a"}, m.Keys())
+	assert.Equal(t, uint64(1), m.Version())
+}
+
+func TestTxMap_Conflict(t *testing.T) {
+	m := NewTxMap[string, int]()
+	require.NoError(t, m.Put("a", 1))
+	tx1 := m.Begin()
+	tx2 := m.Begin()
+	require.NoError(t, tx1.Put("a", 2))
+	require.NoError(t, tx1.Put("b", 2))
+	require.NoError(t, tx2.Put("a", 3))
+	require.NoError(t, tx2.Commit())
+	// tx1 修改的 a 在它开始之后被 tx2 修改了，所有修改都不生效
+	assert.Equal(t, errTxConflict, tx1.Commit())
+	val, _ := m.Get("a")
+	assert.Equal(t, 3, val)
+	_, ok := m.Get("b")
+	assert.False(t, ok)
+
+	// 只读不写的键不会冲突
+	tx3 := m.Begin()
+	_, _ = tx3.Get("a")
+	require.NoError(t, tx3.Put("c", 1))
+	require.NoError(t, m.Put("a", 4))
+	assert.NoError(t, tx3.Commit())
+}
+
+func TestTxMap_Snapshot(t *testing.T) {
+	m := NewTxHashMap[testData, int](10)
+	require.NoError(t, m.Put(newTestData(1), 1))
+	require.NoError(t, m.Put(newTestData(2), 2))
+	snapshot := m.Snapshot()
+	assert.Equal(t, uint64(2), snapshot.Version())
+
+	require.NoError(t, m.Put(newTestData(1), 11))
+	_, ok := m.Delete(newTestData(2))
+	assert.True(t, ok)
+	_, ok = m.Delete(newTestData(2))
+	assert.False(t, ok)
+	require.NoError(t, m.Put(newTestData(3), 3))
+
+	// 快照看到的还是旧版本
+	val, ok := snapshot.Get(newTestData(1))
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	val, ok = snapshot.Get(newTestData(2))
+	assert.True(t, ok)
+	assert.Equal(t, 2, val)
+	_, ok = snapshot.Get(newTestData(3))
+	assert.False(t, ok)
+	assert.ElementsMatch(t, []testData{newTestData(1), newTestData(2)}, snapshot.Keys())
+	assert.ElementsMatch(t, []int{1, 2}, snapshot.Values())
+	assert.Equal(t, int64(2), snapshot.Len())
+
+	assert.ElementsMatch(t, []testData{newTestData(1), newTestData(3)}, m.Keys())
+	assert.Equal(t, int64(2), m.Len())
+
+	snapshot.Close()
+	snapshot.Close()
+	assert.Empty(t, m.active)
+	// 没有快照之后，再次写入会清理掉旧版本
+	require.NoError(t, m.Put(newTestData(1), 111))
+	rec, _ := m.m.Get(newTestData(1))
+	assert.Len(t, rec.versions, 1)
+}
+
+func TestTxMap_SnapshotAt(t *testing.T) {
+	m := NewTxMap[string, int]()
+	require.NoError(t, m.Put("a", 1))
+	snapshot := m.Snapshot()
+	require.NoError(t, m.Put("a", 2))
+	_, ok := m.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), m.Version())
+
+	_, err := m.SnapshotAt(4)
+	assert.Equal(t, errTxVersionNotCommitted, err)
+
+	// 版本 1 还在被 snapshot 使用，它之后的版本都没有被清理
+	for version, want := range map[uint64]int{1: 1, 2: 2} {
+		s, err := m.SnapshotAt(version)
+		require.NoError(t, err)
+		assert.Equal(t, version, s.Version())
+		val, ok := s.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, want, val)
+		s.Close()
+	}
+	s, err := m.SnapshotAt(3)
+	require.NoError(t, err)
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+	s.Close()
+
+	// 没有人使用之后，下一次提交会清理掉旧版本
+	snapshot.Close()
+	require.NoError(t, m.Put("b", 1))
+	for _, version := range []uint64{1, 2, 3} {
+		_, err = m.SnapshotAt(version)
+		assert.Equal(t, errTxVersionPruned, err)
+	}
+	s, err = m.SnapshotAt(4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, s.Keys())
+
+	// SnapshotAt 得到的快照同样会阻止清理
+	require.NoError(t, m.Put("b", 2))
+	val, ok := s.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	s.Close()
+	assert.Empty(t, m.active)
+}
+
+// failPutMap 在写入 fail 的时候返回 error
+type failPutMap[K comparable, V any] struct {
+	mapi[K, V]
+	fail K
+}
+
+var errTestPut = errors.New("put error")
+
+func (m failPutMap[K, V]) Put(key K, val V) error {
+	if key == m.fail {
+		return errTestPut
+	}
+	return m.mapi.Put(key, val)
+}
+
+func TestTxMap_CommitPutError(t *testing.T) {
+	m := newTxMap[string, int](failPutMap[string, *txRecord[int]]{
+		mapi: newBuiltinMap[string, *txRecord[int]](0),
+		fail: "c",
+	}, func() mapi[string, *txWrite[int]] {
+		return newBuiltinMap[string, *txWrite[int]](0)
+	})
+	require.NoError(t, m.Put("a", 1))
+
+	tx := m.Begin()
+	require.NoError(t, tx.Put("a", 2))
+	require.NoError(t, tx.Put("b", 2))
+	require.NoError(t, tx.Put("c", 2))
+	assert.Equal(t, errTestPut, tx.Commit())
+
+	// 提交失败之后什么都没有改变
+	assert.Equal(t, uint64(1), m.Version())
+	assert.Equal(t, int64(1), m.Len())
+	assert.Equal(t, []string{"a"}, m.Keys())
+	val, _ := m.Get("a")
+	assert.Equal(t, 1, val)
+	rec, _ := m.m.Get("a")
+	assert.Len(t, rec.versions, 1)
+	require.NoError(t, m.Put("b", 3))
+	assert.Equal(t, uint64(2), m.Version())
+}
+
+func TestTxMap_Concurrent(t *testing.T) {
+	m := NewTxMap[int, int]()
+	require.NoError(t, m.Put(0, 0))
+	var wg sync.WaitGroup
+	// 每个事务都把 0 号键加一，冲突的时候重试
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for {
+					tx := m.Begin()
+					val, _ := tx.Get(0)
+					_ = tx.Put(0, val+1)
+					if tx.Commit() == nil {
+						break
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				snapshot := m.Snapshot()
+				_, ok := snapshot.Get(0)
+				assert.True(t, ok)
+				snapshot.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	val, _ := m.Get(0)
+	assert.Equal(t, 1000, val)
+}
@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// ArrayBlockingQueue 是基于环形数组的有界阻塞队列，遵循 FIFO
+// 入队和出队共用一把锁
+type ArrayBlockingQueue[T any] struct {
+	data []T
+	// head 是队首的下标，tail 是下一个入队元素的下标
+	head  int
+	tail  int
+	count int
+
+	mutex    sync.Mutex
+	notEmpty *cond
+	notFull  *cond
+}
+
+// NewArrayBlockingQueue 创建一个 ArrayBlockingQueue，capacity 必须大于 0
+func NewArrayBlockingQueue[T any](capacity int) (*ArrayBlockingQueue[T], error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+	q := &ArrayBlockingQueue[T]{
+		data: make([]T, capacity),
+	}
+	q.notEmpty = newCond(&q.mutex)
+	q.notFull = newCond(&q.mutex)
+	return q, nil
+}
+
+func (q *ArrayBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for q.count == len(q.data) {
+		if err := q.notFull.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	q.data[q.tail] = t
+	q.tail = (q.tail + 1) % len(q.data)
+	q.count++
+	q.notEmpty.Signal()
+	return nil
+}
+
+func (q *ArrayBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	if ctx.Err() != nil {
+		var t T
+		return t, ctx.Err()
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for q.count == 0 {
+		if err := q.notEmpty.Wait(ctx); err != nil {
+			var t T
+			return t, err
+		}
+	}
+	t := q.dequeue()
+	q.notFull.Signal()
+	return t, nil
+}
+
+// DrainTo 取出最多 n 个元素，不会阻塞。n <= 0 的时候取出所有元素
+func (q *ArrayBlockingQueue[T]) DrainTo(n int) []T {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if n <= 0 || n > q.count {
+		n = q.count
+	}
+	res := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, q.dequeue())
+	}
+	if n > 0 {
+		q.notFull.Broadcast()
+	}
+	return res
+}
+
+// dequeue 取出队首元素，调用者需要持有锁并且确保队列不为空
+func (q *ArrayBlockingQueue[T]) dequeue() T {
+	t := q.data[q.head]
+	// 置为零值，避免内存泄露
+	var zero T
+	q.data[q.head] = zero
+	q.head = (q.head + 1) % len(q.data)
+	q.count--
+	return t
+}
+
+func (q *ArrayBlockingQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.count
+}
+
+func (q *ArrayBlockingQueue[T]) Cap() int {
+	return len(q.data)
+}
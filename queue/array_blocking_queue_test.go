@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 验证一下 ArrayBlockingQueue 实现了 BlockingQueue 接口
+var _ BlockingQueue[int] = &ArrayBlockingQueue[int]{}
+
+func TestNewArrayBlockingQueue(t *testing.T) {
+	_, err := NewArrayBlockingQueue[int](0)
+	assert.Equal(t, errInvalidCapacity, err)
+	q, err := NewArrayBlockingQueue[int](3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.Cap())
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestArrayBlockingQueue_Enqueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		q       func() *ArrayBlockingQueue[int]
+		timeout time.Duration
+		val     int
+		wantErr error
+		wantLen int
+	}{
+		{
+			name: "空队列",
+			q: func() *ArrayBlockingQueue[int] {
+				q, _ := NewArrayBlockingQueue[int](2)
+				return q
+			},
+			timeout: time.Second,
+			val:     1,
+			wantLen: 1,
+		},
+		{
+			name: "队列满了超时",
+			q: func() *ArrayBlockingQueue[int] {
+				q, _ := NewArrayBlockingQueue[int](2)
+				_ = q.Enqueue(context.Background(), 1)
+				_ = q.Enqueue(context.Background(), 2)
+				return q
+			},
+			timeout: 10 * time.Millisecond,
+			val:     3,
+			wantErr: context.DeadlineExceeded,
+			wantLen: 2,
+		},
+		{
+			name: "ctx 已经超时",
+			q: func() *ArrayBlockingQueue[int] {
+				q, _ := NewArrayBlockingQueue[int](2)
+				return q
+			},
+			timeout: -time.Second,
+			val:     1,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			q := tc.q()
+			err := q.Enqueue(ctx, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLen, q.Len())
+		})
+	}
+}
+
+func TestArrayBlockingQueue_Dequeue(t *testing.T) {
+	q, err := NewArrayBlockingQueue[int](3)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// 环形数组绕回的情况
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Enqueue(context.Background(), i))
+	}
+	for i := 0; i < 2; i++ {
+		val, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, i, val)
+	}
+	require.NoError(t, q.Enqueue(context.Background(), 3))
+	require.NoError(t, q.Enqueue(context.Background(), 4))
+	assert.Equal(t, []int{2, 3, 4}, q.DrainTo(0))
+}
+
+func TestArrayBlockingQueue_Block(t *testing.T) {
+	q, err := NewArrayBlockingQueue[int](1)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(context.Background(), 1))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = q.Dequeue(context.Background())
+	}()
+	// 被阻塞，直到上面的 goroutine 取走了元素
+	require.NoError(t, q.Enqueue(context.Background(), 2))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue(context.Background(), 3)
+	}()
+	assert.Equal(t, []int{2}, q.DrainTo(1))
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, val)
+}
+
+func TestArrayBlockingQueue_DrainTo(t *testing.T) {
+	q, err := NewArrayBlockingQueue[int](5)
+	require.NoError(t, err)
+	assert.Equal(t, []int{}, q.DrainTo(3))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, q.Enqueue(context.Background(), i))
+	}
+	assert.Equal(t, []int{0, 1}, q.DrainTo(2))
+	assert.Equal(t, 3, q.Len())
+	assert.Equal(t, []int{2, 3, 4}, q.DrainTo(10))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestArrayBlockingQueue_Concurrent(t *testing.T) {
+	q, err := NewArrayBlockingQueue[int](4)
+	require.NoError(t, err)
+	testBlockingQueueConcurrent(t, q)
+}
+
+// testBlockingQueueConcurrent 多个生产者和消费者并发读写，所有元素都恰好被取出一次
+func testBlockingQueueConcurrent(t *testing.T, q BlockingQueue[int]) {
+	const producers, n = 8, 500
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		base := i * n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				assert.NoError(t, q.Enqueue(context.Background(), base+j))
+			}
+		}()
+	}
+	var mutex sync.Mutex
+	seen := make(map[int]struct{}, producers*n)
+	var consumers sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for j := 0; j < n; j++ {
+				val, err := q.Dequeue(context.Background())
+				assert.NoError(t, err)
+				mutex.Lock()
+				seen[val] = struct{}{}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	consumers.Wait()
+	assert.Equal(t, producers*n, len(seen))
+}
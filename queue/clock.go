@@ -0,0 +1,31 @@
+package queue
+
+import "time"
+
+// Clock 抽象了计时器，测试的时候可以替换成手动推进的时钟
+type Clock interface {
+	// NewTimer 创建在 d 之后触发的计时器
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer 是 Clock 创建的计时器，语义和 time.Timer 一致
+type Timer interface {
+	// C 返回计时器触发时写入当前时间的 channel
+	C() <-chan time.Time
+	// Stop 停止计时器，如果计时器已经触发或者已经被停止，返回 false
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{Timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
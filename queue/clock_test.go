@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// mockClock 是手动推进的时钟，只有调用 Advance 的时候时间才会流逝
+type mockClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*mockTimer
+	// created 在每次创建计时器的时候都会收到信号，用于等待消费者开始等待
+	created chan struct{}
+}
+
+func newMockClock() *mockClock {
+	return &mockClock{
+		now:     time.Unix(0, 0),
+		created: make(chan struct{}, 1024),
+	}
+}
+
+func (c *mockClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *mockClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	t := &mockTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+	c.timers = append(c.timers, t)
+	c.created <- struct{}{}
+	return t
+}
+
+// Advance 推进时间，并且触发所有到期的计时器
+func (c *mockClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			timers = append(timers, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = timers
+}
+
+type mockTimer struct {
+	clock    *mockClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *mockTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *mockTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
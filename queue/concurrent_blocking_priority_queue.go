@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/internal/queue"
+)
+
+// ConcurrentBlockingPriorityQueue 是并发安全的阻塞优先队列
+// 和 ConcurrentPriorityQueue 不同，队列为空的时候 Dequeue 会阻塞，队列满了的时候 Enqueue 会阻塞
+type ConcurrentBlockingPriorityQueue[T any] struct {
+	pq       *queue.PriorityQueue[T]
+	mutex    sync.Mutex
+	notEmpty *cond
+	notFull  *cond
+	closed   bool
+}
+
+// NewConcurrentBlockingPriorityQueue 创建阻塞优先队列 capacity <= 0 时，为无界队列
+func NewConcurrentBlockingPriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *ConcurrentBlockingPriorityQueue[T] {
+	q := &ConcurrentBlockingPriorityQueue[T]{
+		pq: queue.NewPriorityQueue[T](capacity, compare),
+	}
+	q.notEmpty = newCond(&q.mutex)
+	q.notFull = newCond(&q.mutex)
+	return q
+}
+
+// Enqueue 放入元素，如果队列满了，那么会阻塞直到有空闲位置或者 ctx 被取消
+// 队列关闭之后返回 ErrQueueClosed
+func (c *ConcurrentBlockingPriorityQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for !c.closed && c.isFull() {
+		if err := c.notFull.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if c.closed {
+		return ErrQueueClosed
+	}
+	if err := c.pq.Enqueue(t); err != nil {
+		return err
+	}
+	c.notEmpty.Broadcast()
+	return nil
+}
+
+// Dequeue 取出优先级最高的元素，如果队列为空，那么会阻塞直到有元素或者 ctx 被取消
+// 队列关闭之后依旧可以取出剩余的元素，取完之后返回 ErrQueueClosed
+func (c *ConcurrentBlockingPriorityQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	res, err := c.DequeueBatch(ctx, 1)
+	if err != nil {
+		var t T
+		return t, err
+	}
+	return res[0], nil
+}
+
+// DequeueBatch 按照优先级取出最多 n 个元素
+// 如果队列为空，那么会阻塞直到至少有一个元素，之后不会再等待，有多少取多少
+// n <= 0 的时候取出所有的元素
+func (c *ConcurrentBlockingPriorityQueue[T]) DequeueBatch(ctx context.Context, n int) ([]T, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for !c.closed && c.pq.Len() == 0 {
+		if err := c.notEmpty.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.pq.Len() == 0 {
+		return nil, ErrQueueClosed
+	}
+	if n <= 0 || n > c.pq.Len() {
+		n = c.pq.Len()
+	}
+	res := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		t, _ := c.pq.Dequeue()
+		res = append(res, t)
+	}
+	c.notFull.Broadcast()
+	return res, nil
+}
+
+// Close 关闭队列，正在等待的生产者和消费者都会被唤醒
+// 关闭之后不能再放入元素，但是可以取出剩余的元素。重复关闭没有任何效果
+func (c *ConcurrentBlockingPriorityQueue[T]) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.notEmpty.Broadcast()
+	c.notFull.Broadcast()
+}
+
+func (c *ConcurrentBlockingPriorityQueue[T]) isFull() bool {
+	return c.pq.Cap() > 0 && c.pq.Len() >= c.pq.Cap()
+}
+
+func (c *ConcurrentBlockingPriorityQueue[T]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.pq.Len()
+}
+
+// Cap 无界队列返回 0，有界队列返回创建队列时设置的值
+func (c *ConcurrentBlockingPriorityQueue[T]) Cap() int {
+	return c.pq.Cap()
+}
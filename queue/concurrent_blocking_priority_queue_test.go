@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 验证一下 ConcurrentBlockingPriorityQueue 实现了 BlockingQueue 接口
+var _ BlockingQueue[int] = &ConcurrentBlockingPriorityQueue[int]{}
+
+func TestConcurrentBlockingPriorityQueue(t *testing.T) {
+	q := NewConcurrentBlockingPriorityQueue[int](3, xkit.ComparatorRealNumber[int])
+	assert.Equal(t, 3, q.Cap())
+	for _, val := range []int{3, 1, 2} {
+		require.NoError(t, q.Enqueue(context.Background(), val))
+	}
+	assert.Equal(t, 3, q.Len())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Enqueue(ctx, 4))
+
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	res, err := q.DequeueBatch(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, res)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.DequeueBatch(ctx, 5)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestConcurrentBlockingPriorityQueue_DequeueBatch(t *testing.T) {
+	testCases := []struct {
+		name string
+		max  int
+		want []int
+	}{
+		{name: "部分", max: 2, want: []int{1, 2}},
+		{name: "全部", max: 0, want: []int{1, 2, 3, 4}},
+		{name: "超过长度", max: 10, want: []int{1, 2, 3, 4}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewConcurrentBlockingPriorityQueue[int](0, xkit.ComparatorRealNumber[int])
+			for _, val := range []int{4, 2, 3, 1} {
+				require.NoError(t, q.Enqueue(context.Background(), val))
+			}
+			res, err := q.DequeueBatch(context.Background(), tc.max)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestConcurrentBlockingPriorityQueue_Block(t *testing.T) {
+	q := NewConcurrentBlockingPriorityQueue[int](1, xkit.ComparatorRealNumber[int])
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue(context.Background(), 1)
+	}()
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	require.NoError(t, q.Enqueue(context.Background(), 2))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = q.Dequeue(context.Background())
+	}()
+	require.NoError(t, q.Enqueue(context.Background(), 3))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestConcurrentBlockingPriorityQueue_Close(t *testing.T) {
+	q := NewConcurrentBlockingPriorityQueue[int](1, xkit.ComparatorRealNumber[int])
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := q.Dequeue(context.Background())
+			assert.Equal(t, ErrQueueClosed, err)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	// 等待中的消费者都会被释放
+	q.Close()
+	wg.Wait()
+	q.Close()
+	assert.Equal(t, ErrQueueClosed, q.Enqueue(context.Background(), 1))
+
+	// 关闭之前放入的元素依旧可以取出
+	q = NewConcurrentBlockingPriorityQueue[int](1, xkit.ComparatorRealNumber[int])
+	require.NoError(t, q.Enqueue(context.Background(), 1))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, ErrQueueClosed, q.Enqueue(context.Background(), 2))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	wg.Wait()
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	_, err = q.DequeueBatch(context.Background(), 0)
+	assert.Equal(t, ErrQueueClosed, err)
+}
+
+func TestConcurrentBlockingPriorityQueue_Concurrent(t *testing.T) {
+	testBlockingQueueConcurrent(t, NewConcurrentBlockingPriorityQueue[int](4, xkit.ComparatorRealNumber[int]))
+}
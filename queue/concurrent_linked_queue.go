@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+type clqNode[T any] struct {
+	// val 在节点出队之后会被清空，避免成为哨兵节点之后还引用着已经取走的元素
+	// 其它 goroutine 可能同时在读，所以使用原子指针
+	val  atomic.Pointer[T]
+	next atomic.Pointer[clqNode[T]]
+}
+
+// ConcurrentLinkedQueue 是无锁的无界并发队列，遵循 FIFO
+// 采用了 Michael–Scott 算法：入队和出队都只依赖 CAS，不会阻塞
+type ConcurrentLinkedQueue[T any] struct {
+	// head 是哨兵节点，head.next 才是队首元素
+	head atomic.Pointer[clqNode[T]]
+	// tail 指向最后一个节点或者倒数第二个节点
+	tail atomic.Pointer[clqNode[T]]
+	size atomic.Int64
+}
+
+func NewConcurrentLinkedQueue[T any]() *ConcurrentLinkedQueue[T] {
+	q := &ConcurrentLinkedQueue[T]{}
+	dummy := &clqNode[T]{}
+	q.head.Store(dummy)
+	q.tail.Store(dummy)
+	return q
+}
+
+// Enqueue 将元素放入队尾，因为是无界队列，所以总是返回 nil
+func (c *ConcurrentLinkedQueue[T]) Enqueue(t T) error {
+	node := &clqNode[T]{}
+	node.val.Store(&t)
+	for {
+		tail := c.tail.Load()
+		next := tail.next.Load()
+		if tail != c.tail.Load() {
+			continue
+		}
+		if next != nil {
+			// tail 落后了，帮忙推进
+			c.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, node) {
+			// 失败了也没关系，说明其它 goroutine 已经推进了 tail
+			c.tail.CompareAndSwap(tail, node)
+			c.size.Add(1)
+			return nil
+		}
+	}
+}
+
+// Dequeue 取出队首元素，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentLinkedQueue[T]) Dequeue() (T, error) {
+	for {
+		head := c.head.Load()
+		tail := c.tail.Load()
+		next := head.next.Load()
+		if head != c.head.Load() {
+			continue
+		}
+		if next == nil {
+			var t T
+			return t, ErrEmptyQueue
+		}
+		if head == tail {
+			// 有元素入队了，但是 tail 还没有推进
+			c.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		// 必须在 CAS 之前读取，CAS 之后 next 可能已经被其它 goroutine 取走并且清空
+		val := next.val.Load()
+		if c.head.CompareAndSwap(head, next) {
+			// 只有 CAS 成功的 goroutine 会清空，所以 val 不会是 nil
+			next.val.Store(nil)
+			c.size.Add(-1)
+			return *val, nil
+		}
+	}
+}
+
+// Peek 返回队首元素，但是不会取出，队列为空的时候返回 ErrEmptyQueue
+func (c *ConcurrentLinkedQueue[T]) Peek() (T, error) {
+	for {
+		next := c.head.Load().next.Load()
+		if next == nil {
+			var t T
+			return t, ErrEmptyQueue
+		}
+		if val := next.val.Load(); val != nil {
+			return *val, nil
+		}
+		// next 刚刚被其它 goroutine 取走，重新读取队首
+	}
+}
+
+// Len 返回元素的数量
+// 在并发读写的时候这只是一个近似值
+func (c *ConcurrentLinkedQueue[T]) Len() int {
+	n := c.size.Load()
+	// 出队可能比入队先更新计数
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
@@ -18,6 +18,13 @@ func NewConcurrentPriorityQueue[T any](capacity int, compare xkit.Comparator[T])
 	}
 }
 
+// NewStableConcurrentPriorityQueue 创建稳定的优先队列，优先级相同的元素按照入队的顺序出队
+func NewStableConcurrentPriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *ConcurrentPriorityQueue[T] {
+	return &ConcurrentPriorityQueue[T]{
+		pq: *queue.NewStablePriorityQueue[T](capacity, compare),
+	}
+}
+
 // Cap 无界队列返回 0，有界队列返回创建队列时设置的值
 func (c *ConcurrentPriorityQueue[T]) Cap() int {
 	c.m.RLock()
@@ -48,3 +55,34 @@ func (c *ConcurrentPriorityQueue[T]) Dequeue() (T, error) {
 	defer c.m.Unlock()
 	return c.pq.Dequeue()
 }
+
+// Range 按照堆中的顺序遍历元素，遍历的过程中持有读锁，所以 fn 中不能修改队列
+func (c *ConcurrentPriorityQueue[T]) Range(fn func(index int, t T) error) error {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.pq.Range(fn)
+}
+
+// AsSlice 按照堆中的顺序返回所有元素的副本
+func (c *ConcurrentPriorityQueue[T]) AsSlice() []T {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.pq.AsSlice()
+}
+
+// RangeSorted 按照出队的顺序遍历元素，遍历的是元素的副本，fn 中可以修改队列
+func (c *ConcurrentPriorityQueue[T]) RangeSorted(fn func(index int, t T) error) error {
+	for i, t := range c.AsSortedSlice() {
+		if err := fn(i, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AsSortedSlice 按照出队的顺序返回所有元素的副本
+func (c *ConcurrentPriorityQueue[T]) AsSortedSlice() []T {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.pq.AsSortedSlice()
+}
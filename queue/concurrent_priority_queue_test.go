@@ -295,3 +295,39 @@ func ExampleNewConcurrentPriorityQueue() {
 	// Output:
 	// [1 2 3]
 }
+
+func TestConcurrentPriorityQueue_Stable(t *testing.T) {
+	type job struct {
+		priority int
+		id       int
+	}
+	q := NewStableConcurrentPriorityQueue[job](0, func(src, dst job) int {
+		return xkit.ComparatorRealNumber[int](src.priority, dst.priority)
+	})
+	for i := 0; i < 20; i++ {
+		require.NoError(t, q.Enqueue(job{priority: i % 2, id: i}))
+	}
+	sorted := q.AsSortedSlice()
+	assert.Len(t, q.AsSlice(), 20)
+	var ranged []job
+	require.NoError(t, q.RangeSorted(func(index int, t job) error {
+		ranged = append(ranged, t)
+		return nil
+	}))
+	assert.Equal(t, sorted, ranged)
+	cnt := 0
+	require.NoError(t, q.Range(func(index int, t job) error {
+		cnt++
+		return nil
+	}))
+	assert.Equal(t, 20, cnt)
+
+	for i := 0; i < 20; i++ {
+		j, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, sorted[i], j)
+		// 同优先级的任务按照入队顺序出队
+		assert.Equal(t, i/10, j.priority)
+		assert.Equal(t, (i%10)*2+j.priority, j.id)
+	}
+}
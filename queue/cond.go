@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cond 是支持 context 的条件变量
+// sync.Cond 的 Wait 无法被取消，所以这里给每个等待者分配一个 channel，通过关闭 channel 来唤醒它
+// 没有等待者的时候 Signal 和 Broadcast 什么都不做
+// 所有的方法都必须在持有 l 的时候调用
+type cond struct {
+	l sync.Locker
+	// waiters 按照开始等待的顺序排列
+	waiters []chan struct{}
+}
+
+func newCond(l sync.Locker) *cond {
+	return &cond{
+		l: l,
+	}
+}
+
+// Wait 释放锁并等待 Signal、Broadcast 或者 ctx 超时，返回之前会重新获得锁
+// 和 sync.Cond 一样，被唤醒之后调用者需要重新检查条件
+func (c *cond) Wait(ctx context.Context) error {
+	return c.WaitTimeout(ctx, nil)
+}
+
+// WaitTimeout 和 Wait 一样，但是 timeout 有信号的时候也会返回 nil
+func (c *cond) WaitTimeout(ctx context.Context, timeout <-chan time.Time) error {
+	ch := make(chan struct{})
+	c.waiters = append(c.waiters, ch)
+	c.l.Unlock()
+	var err error
+	select {
+	case <-ch:
+	case <-timeout:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	c.l.Lock()
+	// 已经不在 waiters 中，说明在超时的同时也被唤醒了
+	// 这时候返回 nil 让调用者重新检查条件，避免 Signal 的唤醒被丢掉
+	if !c.remove(ch) {
+		return nil
+	}
+	return err
+}
+
+// Signal 唤醒等待时间最长的一个等待者
+func (c *cond) Signal() {
+	if len(c.waiters) == 0 {
+		return
+	}
+	close(c.waiters[0])
+	c.waiters[0] = nil
+	c.waiters = c.waiters[1:]
+}
+
+// Broadcast 唤醒所有的等待者
+func (c *cond) Broadcast() {
+	for i, ch := range c.waiters {
+		close(ch)
+		c.waiters[i] = nil
+	}
+	c.waiters = c.waiters[:0]
+}
+
+// remove 将 ch 从 waiters 中移除，ch 不在 waiters 中的时候返回 false
+func (c *cond) remove(ch chan struct{}) bool {
+	for i, w := range c.waiters {
+		if w == ch {
+			copy(c.waiters[i:], c.waiters[i+1:])
+			c.waiters[len(c.waiters)-1] = nil
+			c.waiters = c.waiters[:len(c.waiters)-1]
+			return true
+		}
+	}
+	return false
+}
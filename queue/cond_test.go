@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startWaiters 启动 n 个等待者，等它们都开始等待之后返回，被唤醒的等待者会写入 woken
+func startWaiters(ctx context.Context, c *cond, mutex *sync.Mutex, n int) <-chan error {
+	woken := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			woken <- c.Wait(ctx)
+		}()
+	}
+	for {
+		mutex.Lock()
+		cnt := len(c.waiters)
+		mutex.Unlock()
+		if cnt == n {
+			return woken
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCond_Signal(t *testing.T) {
+	var mutex sync.Mutex
+	c := newCond(&mutex)
+	woken := startWaiters(context.Background(), c, &mutex, 3)
+
+	mutex.Lock()
+	c.Signal()
+	mutex.Unlock()
+	assert.NoError(t, <-woken)
+	// 只唤醒了一个
+	select {
+	case <-woken:
+		t.Fatal("Signal 唤醒了多个等待者")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	mutex.Lock()
+	c.Broadcast()
+	assert.Empty(t, c.waiters)
+	mutex.Unlock()
+	assert.NoError(t, <-woken)
+	assert.NoError(t, <-woken)
+}
+
+func TestCond_Cancel(t *testing.T) {
+	var mutex sync.Mutex
+	c := newCond(&mutex)
+	ctx, cancel := context.WithCancel(context.Background())
+	woken := startWaiters(ctx, c, &mutex, 1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-woken)
+	// 被取消的等待者已经不在 waiters 中，Signal 不会唤醒它
+	mutex.Lock()
+	assert.Empty(t, c.waiters)
+	mutex.Unlock()
+
+	// 取消的同时被唤醒，视为被唤醒
+	ctx, cancel = context.WithCancel(context.Background())
+	woken = startWaiters(ctx, c, &mutex, 1)
+	mutex.Lock()
+	c.Signal()
+	cancel()
+	mutex.Unlock()
+	assert.NoError(t, <-woken)
+}
+
+func TestCond_NoWaiters(t *testing.T) {
+	var mutex sync.Mutex
+	c := newCond(&mutex)
+	mutex.Lock()
+	defer mutex.Unlock()
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Signal()
+		c.Broadcast()
+	})
+	assert.Equal(t, float64(0), allocs)
+}
@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WeiXinao/xkit/internal/queue"
+)
+
+// Delayable 是可以放入 DelayQueue 的元素
+type Delayable interface {
+	// Delay 返回元素还要多久才能被取出，小于等于 0 表示已经到期
+	Delay() time.Duration
+}
+
+// DelayQueue 延时队列
+// 元素只有在到期之后才能被取出，越早到期的元素越先被取出
+// 所有的等待者共用计时器之外的状态，不需要为每个元素启动 goroutine
+type DelayQueue[T Delayable] struct {
+	mutex    sync.Mutex
+	q        *queue.PriorityQueue[T]
+	notEmpty *cond
+	notFull  *cond
+	clock    Clock
+}
+
+// NewDelayQueue 创建延时队列 capacity <= 0 时，为无界队列
+func NewDelayQueue[T Delayable](capacity int) *DelayQueue[T] {
+	return NewDelayQueueWithClock[T](capacity, realClock{})
+}
+
+// NewDelayQueueWithClock 创建使用指定时钟计时的延时队列 capacity <= 0 时，为无界队列
+// 元素的 Delay 也应该基于同一个时钟计算，否则等待的时间会不准确
+func NewDelayQueueWithClock[T Delayable](capacity int, clock Clock) *DelayQueue[T] {
+	dq := &DelayQueue[T]{
+		q: queue.NewPriorityQueue[T](capacity, func(src, dst T) int {
+			srcDelay, dstDelay := src.Delay(), dst.Delay()
+			if srcDelay < dstDelay {
+				return -1
+			} else if srcDelay == dstDelay {
+				return 0
+			}
+			return 1
+		}),
+		clock: clock,
+	}
+	dq.notEmpty = newCond(&dq.mutex)
+	dq.notFull = newCond(&dq.mutex)
+	return dq
+}
+
+// Enqueue 放入元素，如果队列满了，那么会阻塞直到有空闲位置或者 ctx 被取消
+// 如果新元素比原本的队首元素更早到期，那么正在等待的 Dequeue 会被提前唤醒
+func (d *DelayQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for d.q.Cap() > 0 && d.q.Len() >= d.q.Cap() {
+		if err := d.notFull.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	head, peekErr := d.q.Peek()
+	if err := d.q.Enqueue(t); err != nil {
+		return err
+	}
+	if peekErr != nil || t.Delay() < head.Delay() {
+		d.notEmpty.Broadcast()
+	}
+	return nil
+}
+
+// Dequeue 取出到期的队首元素
+// 如果队列为空或者队首元素还没有到期，那么会阻塞直到有元素到期或者 ctx 被取消
+func (d *DelayQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	if ctx.Err() != nil {
+		var t T
+		return t, ctx.Err()
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for {
+		head, err := d.q.Peek()
+		if err != nil {
+			if err = d.notEmpty.Wait(ctx); err != nil {
+				var t T
+				return t, err
+			}
+			continue
+		}
+		delay := head.Delay()
+		if delay <= 0 {
+			t, _ := d.q.Dequeue()
+			d.notFull.Broadcast()
+			// 其它的等待者需要重新计算等待时间
+			d.notEmpty.Broadcast()
+			return t, nil
+		}
+		tm := d.clock.NewTimer(delay)
+		err = d.notEmpty.WaitTimeout(ctx, tm.C())
+		tm.Stop()
+		if err != nil {
+			var t T
+			return t, err
+		}
+	}
+}
+
+func (d *DelayQueue[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.q.Len()
+}
+
+// Cap 无界队列返回 0，有界队列返回创建队列时设置的值
+func (d *DelayQueue[T]) Cap() int {
+	return d.q.Cap()
+}
@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 验证一下 DelayQueue 实现了 BlockingQueue 接口
+var _ BlockingQueue[delayElem] = &DelayQueue[delayElem]{}
+
+type delayElem struct {
+	val      int
+	deadline time.Time
+	clock    *mockClock
+}
+
+func (d delayElem) Delay() time.Duration {
+	return d.deadline.Sub(d.clock.Now())
+}
+
+func newTestDelayQueue(capacity int) (*DelayQueue[delayElem], *mockClock) {
+	clk := newMockClock()
+	return NewDelayQueueWithClock[delayElem](capacity, clk), clk
+}
+
+func (c *mockClock) elem(val int, delay time.Duration) delayElem {
+	return delayElem{val: val, deadline: c.Now().Add(delay), clock: c}
+}
+
+func TestDelayQueue_Dequeue(t *testing.T) {
+	q, clk := newTestDelayQueue(0)
+	assert.Equal(t, 0, q.Cap())
+	for i, delay := range []time.Duration{3 * time.Second, time.Second, 0, 2 * time.Second} {
+		require.NoError(t, q.Enqueue(context.Background(), clk.elem(i, delay)))
+	}
+	assert.Equal(t, 4, q.Len())
+
+	// 已经到期的元素可以直接取出
+	elem, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, elem.val)
+
+	res := make(chan int)
+	go func() {
+		for i := 0; i < 3; i++ {
+			elem, err := q.Dequeue(context.Background())
+			assert.NoError(t, err)
+			res <- elem.val
+		}
+	}()
+	for _, want := range []int{1, 3, 0} {
+		<-clk.created
+		select {
+		case <-res:
+			t.Fatal("元素还没到期")
+		default:
+		}
+		clk.Advance(time.Second)
+		assert.Equal(t, want, <-res)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestDelayQueue_EarlierElement(t *testing.T) {
+	q, clk := newTestDelayQueue(0)
+	require.NoError(t, q.Enqueue(context.Background(), clk.elem(1, time.Hour)))
+	res := make(chan int)
+	go func() {
+		elem, err := q.Dequeue(context.Background())
+		assert.NoError(t, err)
+		res <- elem.val
+	}()
+	<-clk.created
+	// 放入更早到期的元素，等待者会被唤醒并且重新计时
+	require.NoError(t, q.Enqueue(context.Background(), clk.elem(2, time.Second)))
+	<-clk.created
+	clk.Advance(time.Second)
+	assert.Equal(t, 2, <-res)
+}
+
+func TestDelayQueue_Timeout(t *testing.T) {
+	q, clk := newTestDelayQueue(1)
+	assert.Equal(t, 1, q.Cap())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	require.NoError(t, q.Enqueue(context.Background(), clk.elem(1, time.Second)))
+	// 元素没有到期
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	// 队列满了
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Enqueue(ctx, clk.elem(2, 0)))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestDelayQueue_RealClock(t *testing.T) {
+	q := NewDelayQueue[realDelayElem](0)
+	start := time.Now()
+	require.NoError(t, q.Enqueue(context.Background(), realDelayElem(start.Add(20*time.Millisecond))))
+	elem, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.False(t, time.Now().Before(time.Time(elem)))
+}
+
+type realDelayElem time.Time
+
+func (r realDelayElem) Delay() time.Duration {
+	return time.Until(time.Time(r))
+}
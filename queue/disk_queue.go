@@ -0,0 +1,494 @@
+package queue
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	diskQueueSegmentSuffix      = ".seg"
+	diskQueueCheckpointFile     = "checkpoint"
+	diskQueueDefaultSegmentSize = 64 << 20
+	// 每条记录的头部：4 字节的长度以及 4 字节的 CRC32 校验和
+	diskQueueHeaderSize = 8
+	// 每取出这么多条记录更新一次 checkpoint
+	diskQueueCheckpointInterval = 128
+)
+
+var (
+	errDiskQueueClosed     = errors.New("xkit: DiskQueue 已经关闭")
+	errDiskQueueRecordSize = errors.New("xkit: 记录的长度超过了段文件的大小")
+	errDiskQueueCorrupted  = errors.New("xkit: DiskQueue 的记录已经损坏")
+)
+
+// Codec 负责元素和字节之间的转换
+type Codec[T any] interface {
+	Encode(t T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec 使用 JSON 编解码
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(t T) ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var t T
+	err := json.Unmarshal(data, &t)
+	return t, err
+}
+
+// DiskQueue 是基于本地文件的持久化队列，遵循 FIFO
+// 元素编码之后追加写入到目录下的段文件中，一个段文件写满之后创建新的段文件，
+// 读到的位置记录在 checkpoint 文件中，已经读完的段文件会被删除
+// 每条记录都带有 CRC32 校验和。重新打开的时候会从 checkpoint 开始重放所有的段文件，
+// 丢弃掉崩溃时没有写完整的记录
+// 注意：
+//   - 写入没有调用 fsync，进程崩溃不会丢数据，但是机器掉电可能会丢失最近的写入，需要的话可以调用 Sync
+//   - 为了避免每次 Dequeue 都写文件，checkpoint 只在每取出 128 个元素、切换段文件、调用 Sync 或者 Close 的时候更新，
+//     进程崩溃之后，最后一次更新 checkpoint 之后取出的元素会被再次取出，所以元素至少被取出一次
+//   - 同一个目录只能被一个 DiskQueue 使用
+type DiskQueue[T any] struct {
+	mutex       sync.Mutex
+	dir         string
+	codec       Codec[T]
+	segmentSize int64
+
+	readSeg   uint64
+	readOff   int64
+	readFile  *os.File
+	writeSeg  uint64
+	writeOff  int64
+	writeFile *os.File
+	// length 是还没有被读取的记录数量
+	length int
+	// unsaved 是上一次更新 checkpoint 之后取出的记录数量
+	unsaved int
+	closed  bool
+}
+
+// NewDiskQueue 打开或者创建 dir 目录下的 DiskQueue
+// segmentSize 是单个段文件的大小上限，<= 0 的时候使用默认值 64MB
+func NewDiskQueue[T any](dir string, codec Codec[T], segmentSize int64) (*DiskQueue[T], error) {
+	if segmentSize <= 0 {
+		segmentSize = diskQueueDefaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &DiskQueue[T]{
+		dir:         dir,
+		codec:       codec,
+		segmentSize: segmentSize,
+	}
+	if err := q.recover(); err != nil {
+		q.closeFiles()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Enqueue 将元素追加写入到段文件
+func (q *DiskQueue[T]) Enqueue(t T) error {
+	data, err := q.codec.Encode(t)
+	if err != nil {
+		return err
+	}
+	recordSize := int64(diskQueueHeaderSize + len(data))
+	if recordSize > q.segmentSize {
+		return errDiskQueueRecordSize
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return errDiskQueueClosed
+	}
+	if q.writeOff > 0 && q.writeOff+recordSize > q.segmentSize {
+		if err = q.openWriteSegment(q.writeSeg + 1); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, recordSize)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[diskQueueHeaderSize:], data)
+	if _, err = q.writeFile.Write(buf); err != nil {
+		// 写了一半的记录在下一次打开的时候会被丢弃，这里也要回到写之前的位置
+		_ = q.writeFile.Truncate(q.writeOff)
+		return err
+	}
+	q.writeOff += recordSize
+	q.length++
+	return nil
+}
+
+// Dequeue 读取队首元素
+// 队列为空的时候返回 ErrEmptyQueue。解码失败的时候返回解码的错误，记录仍然留在队首
+// 遇到损坏的记录时，和重新打开的时候一样丢弃当前段文件中从这条记录开始的内容，并且返回 errDiskQueueCorrupted，
+// 之后的 Dequeue 会从剩下的记录继续读
+func (q *DiskQueue[T]) Dequeue() (T, error) {
+	var t T
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return t, errDiskQueueClosed
+	}
+	if q.length == 0 {
+		return t, ErrEmptyQueue
+	}
+	size, err := fileSize(q.readFile)
+	if err != nil {
+		return t, err
+	}
+	// 当前段文件已经读完，切换到下一个段文件并且删除已经读完的段文件
+	for q.readSeg < q.writeSeg && q.readOff >= size {
+		if err = q.openReadSegment(q.readSeg+1, 0); err != nil {
+			return t, err
+		}
+		if err = q.saveCheckpoint(); err != nil {
+			return t, err
+		}
+		if err = q.removeSegmentsBefore(q.readSeg); err != nil {
+			return t, err
+		}
+		if size, err = fileSize(q.readFile); err != nil {
+			return t, err
+		}
+	}
+	data, err := readRecord(q.readFile, q.readOff, size)
+	if err == errDiskQueueCorrupted {
+		if e := q.dropCorrupted(); e != nil {
+			return t, e
+		}
+		return t, err
+	}
+	if err != nil {
+		return t, err
+	}
+	if t, err = q.codec.Decode(data); err != nil {
+		return t, err
+	}
+	q.readOff += int64(diskQueueHeaderSize + len(data))
+	q.length--
+	q.unsaved++
+	if q.unsaved >= diskQueueCheckpointInterval {
+		if err = q.saveCheckpoint(); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+func (q *DiskQueue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.length
+}
+
+// Sync 更新 checkpoint，并且将写入的数据刷到磁盘上
+func (q *DiskQueue[T]) Sync() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return errDiskQueueClosed
+	}
+	if q.unsaved > 0 {
+		if err := q.saveCheckpoint(); err != nil {
+			return err
+		}
+	}
+	return q.writeFile.Sync()
+}
+
+// Close 关闭打开的文件，之后不能再使用这个队列
+func (q *DiskQueue[T]) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	var err error
+	if q.unsaved > 0 {
+		err = q.saveCheckpoint()
+	}
+	if e := q.closeFiles(); e != nil {
+		err = e
+	}
+	return err
+}
+
+// recover 根据 checkpoint 和段文件恢复队列的状态
+func (q *DiskQueue[T]) recover() error {
+	segs, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+	if len(segs) == 0 {
+		segs = []uint64{0}
+	}
+	readSeg, readOff, ok := q.loadCheckpoint()
+	// checkpoint 不存在、损坏或者指向的段文件已经不在了，从最早的段文件开始读
+	if !ok || readSeg < segs[0] || readSeg > segs[len(segs)-1] {
+		readSeg, readOff = segs[0], 0
+	}
+	if err = q.removeSegmentsBefore(readSeg); err != nil {
+		return err
+	}
+	// 重放所有没有读完的段文件，统计记录数量，并且截断损坏的记录
+	for _, seg := range segs {
+		if seg < readSeg {
+			continue
+		}
+		start := int64(0)
+		if seg == readSeg {
+			start = readOff
+		}
+		cnt, size, err := q.replaySegment(seg, start)
+		if err != nil {
+			return err
+		}
+		// 被截断的记录可能已经超过了 checkpoint 记录的位置
+		if seg == readSeg && readOff > size {
+			readOff = size
+		}
+		q.length += cnt
+	}
+	if err = q.openWriteSegment(segs[len(segs)-1]); err != nil {
+		return err
+	}
+	return q.openReadSegment(readSeg, readOff)
+}
+
+// replaySegment 从 start 开始校验段文件中的记录，返回完整记录的数量以及截断之后的文件大小
+// 第一条不完整或者校验失败的记录以及它之后的内容都会被截断
+func (q *DiskQueue[T]) replaySegment(seg uint64, start int64) (int, int64, error) {
+	f, err := os.OpenFile(q.segmentPath(seg), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	size, err := fileSize(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > size {
+		return 0, size, nil
+	}
+	cnt, off := 0, start
+	for off < size {
+		data, err := readRecord(f, off, size)
+		if err == errDiskQueueCorrupted {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		off += int64(diskQueueHeaderSize + len(data))
+		cnt++
+	}
+	if off < size {
+		if err = f.Truncate(off); err != nil {
+			return 0, 0, err
+		}
+	}
+	return cnt, off, nil
+}
+
+// dropCorrupted 丢弃当前段文件中从 readOff 开始已经损坏的内容，处理方式和 replaySegment 一致
+// 如果当前段文件就是正在写入的段文件，那么直接截断；否则跳到下一个段文件，并且重新统计剩下的记录数量
+func (q *DiskQueue[T]) dropCorrupted() error {
+	if q.readSeg == q.writeSeg {
+		if err := q.writeFile.Truncate(q.readOff); err != nil {
+			return err
+		}
+		q.writeOff = q.readOff
+		q.length = 0
+		return nil
+	}
+	segs, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+	length := 0
+	next := q.writeSeg
+	for i := len(segs) - 1; i >= 0 && segs[i] > q.readSeg; i-- {
+		cnt, size, err := q.replaySegment(segs[i], 0)
+		if err != nil {
+			return err
+		}
+		if segs[i] == q.writeSeg {
+			q.writeOff = size
+		}
+		length += cnt
+		next = segs[i]
+	}
+	if err = q.openReadSegment(next, 0); err != nil {
+		return err
+	}
+	if err = q.saveCheckpoint(); err != nil {
+		return err
+	}
+	q.length = length
+	return q.removeSegmentsBefore(q.readSeg)
+}
+
+func (q *DiskQueue[T]) openWriteSegment(seg uint64) error {
+	f, err := os.OpenFile(q.segmentPath(seg), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	size, err := fileSize(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if q.writeFile != nil {
+		_ = q.writeFile.Close()
+	}
+	q.writeFile, q.writeSeg, q.writeOff = f, seg, size
+	return nil
+}
+
+func (q *DiskQueue[T]) openReadSegment(seg uint64, off int64) error {
+	f, err := os.Open(q.segmentPath(seg))
+	if err != nil {
+		return err
+	}
+	if q.readFile != nil {
+		_ = q.readFile.Close()
+	}
+	q.readFile, q.readSeg, q.readOff = f, seg, off
+	return nil
+}
+
+func (q *DiskQueue[T]) removeSegmentsBefore(seg uint64) error {
+	segs, err := q.listSegments()
+	if err != nil {
+		return err
+	}
+	for _, s := range segs {
+		if s >= seg {
+			break
+		}
+		if err = os.Remove(q.segmentPath(s)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listSegments 返回所有段文件的编号，从小到大排列
+func (q *DiskQueue[T]) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, diskQueueSegmentSuffix) {
+			continue
+		}
+		seg, err := strconv.ParseUint(strings.TrimSuffix(name, diskQueueSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool {
+		return segs[i] < segs[j]
+	})
+	return segs, nil
+}
+
+func (q *DiskQueue[T]) segmentPath(seg uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seg, diskQueueSegmentSuffix))
+}
+
+// checkpoint 的格式：8 字节段编号、8 字节偏移量以及前面 16 字节的 CRC32 校验和
+func (q *DiskQueue[T]) saveCheckpoint() error {
+	buf := make([]byte, 20)
+	binary.BigEndian.PutUint64(buf[0:8], q.readSeg)
+	binary.BigEndian.PutUint64(buf[8:16], uint64(q.readOff))
+	binary.BigEndian.PutUint32(buf[16:20], crc32.ChecksumIEEE(buf[:16]))
+	path := filepath.Join(q.dir, diskQueueCheckpointFile)
+	// 先写临时文件再重命名，保证 checkpoint 不会只写了一半
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	q.unsaved = 0
+	return nil
+}
+
+func (q *DiskQueue[T]) loadCheckpoint() (uint64, int64, bool) {
+	buf, err := os.ReadFile(filepath.Join(q.dir, diskQueueCheckpointFile))
+	if err != nil || len(buf) != 20 || crc32.ChecksumIEEE(buf[:16]) != binary.BigEndian.Uint32(buf[16:20]) {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(buf[0:8]), int64(binary.BigEndian.Uint64(buf[8:16])), true
+}
+
+func (q *DiskQueue[T]) closeFiles() error {
+	var err error
+	if q.readFile != nil {
+		err = q.readFile.Close()
+	}
+	if q.writeFile != nil {
+		if e := q.writeFile.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// readRecord 读取 off 位置的一条记录，返回记录的内容，size 是文件的大小
+func readRecord(f *os.File, off int64, size int64) ([]byte, error) {
+	header := make([]byte, diskQueueHeaderSize)
+	if _, err := f.ReadAt(header, off); err != nil {
+		if err == io.EOF {
+			return nil, errDiskQueueCorrupted
+		}
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[0:4]))
+	// 长度本身可能就是坏的，先检查一下，避免分配过大的内存
+	if off+diskQueueHeaderSize+length > size {
+		return nil, errDiskQueueCorrupted
+	}
+	data := make([]byte, length)
+	if _, err := f.ReadAt(data, off+diskQueueHeaderSize); err != nil {
+		if err == io.EOF {
+			return nil, errDiskQueueCorrupted
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errDiskQueueCorrupted
+	}
+	return data, nil
+}
+
+func fileSize(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,283 @@
+package queue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 验证一下 DiskQueue 实现了 Queue 接口
+var _ Queue[int] = &DiskQueue[int]{}
+
+type diskQueueItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestDiskQueue(t *testing.T, dir string, segmentSize int64) *DiskQueue[diskQueueItem] {
+	q, err := NewDiskQueue[diskQueueItem](dir, JSONCodec[diskQueueItem]{}, segmentSize)
+	require.NoError(t, err)
+	return q
+}
+
+func enqueueDiskItems(t *testing.T, q *DiskQueue[diskQueueItem], from, to int) {
+	for i := from; i < to; i++ {
+		require.NoError(t, q.Enqueue(diskQueueItem{ID: i, Name: "item"}))
+	}
+}
+
+func dequeueDiskItems(t *testing.T, q *DiskQueue[diskQueueItem], from, to int) {
+	for i := from; i < to; i++ {
+		item, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, i, item.ID)
+	}
+}
+
+func countSegments(t *testing.T, dir string) int {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+diskQueueSegmentSuffix))
+	require.NoError(t, err)
+	return len(matches)
+}
+
+func TestDiskQueue(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestDiskQueue(t, dir, 0)
+	_, err := q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	enqueueDiskItems(t, q, 0, 10)
+	assert.Equal(t, 10, q.Len())
+	dequeueDiskItems(t, q, 0, 4)
+	enqueueDiskItems(t, q, 10, 12)
+	assert.Equal(t, 8, q.Len())
+	dequeueDiskItems(t, q, 4, 12)
+	_, err = q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+	require.NoError(t, q.Sync())
+
+	require.NoError(t, q.Close())
+	require.NoError(t, q.Close())
+	assert.Equal(t, errDiskQueueClosed, q.Enqueue(diskQueueItem{}))
+	_, err = q.Dequeue()
+	assert.Equal(t, errDiskQueueClosed, err)
+	assert.Equal(t, errDiskQueueClosed, q.Sync())
+}
+
+func TestDiskQueue_Segments(t *testing.T) {
+	dir := t.TempDir()
+	// 每个段文件大概能放 4 条记录
+	q := newTestDiskQueue(t, dir, 128)
+	assert.Equal(t, errDiskQueueRecordSize, q.Enqueue(diskQueueItem{Name: string(make([]byte, 128))}))
+
+	enqueueDiskItems(t, q, 0, 20)
+	assert.Equal(t, 5, countSegments(t, dir))
+	dequeueDiskItems(t, q, 0, 10)
+	// 读完的段文件被删除了
+	assert.Equal(t, 3, countSegments(t, dir))
+	dequeueDiskItems(t, q, 10, 20)
+	enqueueDiskItems(t, q, 20, 21)
+	dequeueDiskItems(t, q, 20, 21)
+	assert.Equal(t, 1, countSegments(t, dir))
+	assert.Equal(t, 0, q.Len())
+	require.NoError(t, q.Close())
+}
+
+func TestDiskQueue_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestDiskQueue(t, dir, 128)
+	enqueueDiskItems(t, q, 0, 20)
+	dequeueDiskItems(t, q, 0, 7)
+	require.NoError(t, q.Close())
+
+	q = newTestDiskQueue(t, dir, 128)
+	assert.Equal(t, 13, q.Len())
+	dequeueDiskItems(t, q, 7, 10)
+	enqueueDiskItems(t, q, 20, 25)
+	require.NoError(t, q.Close())
+
+	q = newTestDiskQueue(t, dir, 128)
+	assert.Equal(t, 15, q.Len())
+	dequeueDiskItems(t, q, 10, 25)
+	require.NoError(t, q.Close())
+}
+
+func TestDiskQueue_Recover(t *testing.T) {
+	testCases := []struct {
+		name string
+		// corrupt 在关闭队列之后破坏文件
+		corrupt func(t *testing.T, dir string, lastSeg string)
+		// wantIDs 是重新打开之后能读到的元素
+		wantIDs []int
+	}{
+		{
+			name: "没有写完的记录",
+			corrupt: func(t *testing.T, dir string, lastSeg string) {
+				f, err := os.OpenFile(lastSeg, os.O_WRONLY|os.O_APPEND, 0o644)
+				require.NoError(t, err)
+				_, err = f.Write([]byte{0, 0, 0, 100, 1, 2, 3})
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			},
+			wantIDs: []int{3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name: "校验和不对",
+			corrupt: func(t *testing.T, dir string, lastSeg string) {
+				data, err := os.ReadFile(lastSeg)
+				require.NoError(t, err)
+				// 破坏最后一个段文件中的最后一条记录
+				data[len(data)-2] ^= 0xff
+				require.NoError(t, os.WriteFile(lastSeg, data, 0o644))
+			},
+			wantIDs: []int{3, 4, 5, 6, 7, 8},
+		},
+		{
+			name: "checkpoint 损坏",
+			corrupt: func(t *testing.T, dir string, lastSeg string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, diskQueueCheckpointFile), []byte("bad"), 0o644))
+			},
+			// 第一个段文件里面的记录会被重新读一次
+			wantIDs: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q := newTestDiskQueue(t, dir, 128)
+			enqueueDiskItems(t, q, 0, 10)
+			dequeueDiskItems(t, q, 0, 3)
+			require.NoError(t, q.Close())
+			tc.corrupt(t, dir, q.segmentPath(q.writeSeg))
+
+			q = newTestDiskQueue(t, dir, 128)
+			assert.Equal(t, len(tc.wantIDs), q.Len())
+			for _, id := range tc.wantIDs {
+				item, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, id, item.ID)
+			}
+			// 恢复之后可以继续写入
+			enqueueDiskItems(t, q, 100, 101)
+			dequeueDiskItems(t, q, 100, 101)
+			require.NoError(t, q.Close())
+		})
+	}
+}
+
+type failDecodeCodec struct {
+	JSONCodec[int]
+}
+
+var errTestDecode = errors.New("decode error")
+
+func (failDecodeCodec) Decode(data []byte) (int, error) {
+	return 0, errTestDecode
+}
+
+func TestDiskQueue_DecodeError(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewDiskQueue[int](dir, failDecodeCodec{}, 0)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(1))
+	_, err = q.Dequeue()
+	assert.Equal(t, errTestDecode, err)
+	// 解码失败的记录还在队首
+	assert.Equal(t, 1, q.Len())
+	_, err = q.Dequeue()
+	assert.Equal(t, errTestDecode, err)
+	require.NoError(t, q.Close())
+
+	q, err = NewDiskQueue[int](dir, JSONCodec[int]{}, 0)
+	require.NoError(t, err)
+	val, err := q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	require.NoError(t, q.Close())
+}
+
+func TestDiskQueue_Checkpoint(t *testing.T) {
+	dir := t.TempDir()
+	q := newTestDiskQueue(t, dir, 0)
+	enqueueDiskItems(t, q, 0, 300)
+	dequeueDiskItems(t, q, 0, 130)
+	// 模拟进程崩溃，不调用 Close
+	require.NoError(t, q.closeFiles())
+
+	// 只有前 128 个元素的 checkpoint 被保存了
+	q = newTestDiskQueue(t, dir, 0)
+	assert.Equal(t, 300-diskQueueCheckpointInterval, q.Len())
+	dequeueDiskItems(t, q, diskQueueCheckpointInterval, 140)
+	require.NoError(t, q.Sync())
+	dequeueDiskItems(t, q, 140, 150)
+	require.NoError(t, q.closeFiles())
+
+	q = newTestDiskQueue(t, dir, 0)
+	assert.Equal(t, 160, q.Len())
+	dequeueDiskItems(t, q, 140, 150)
+	require.NoError(t, q.Close())
+
+	q = newTestDiskQueue(t, dir, 0)
+	assert.Equal(t, 150, q.Len())
+	require.NoError(t, q.Close())
+}
+
+func TestDiskQueue_CorruptedAfterOpen(t *testing.T) {
+	// 每条记录 30 字节，每个段文件放 4 条记录：0-3、4-7、8-9
+	const recordSize = 30
+	testCases := []struct {
+		name string
+		// corruptID 是被破坏的记录
+		corruptID int
+		// wantIDs 是遇到损坏的记录之后还能读到的元素
+		wantIDs []int
+	}{
+		{
+			name:      "已经写完的段文件",
+			corruptID: 2,
+			// 3 和 2 在同一个段文件中，也被丢弃了
+			wantIDs: []int{4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:      "正在写入的段文件",
+			corruptID: 8,
+			wantIDs:   []int{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			q := newTestDiskQueue(t, dir, 128)
+			enqueueDiskItems(t, q, 0, 10)
+			dequeueDiskItems(t, q, 0, tc.corruptID)
+
+			f, err := os.OpenFile(q.segmentPath(uint64(tc.corruptID/4)), os.O_WRONLY, 0o644)
+			require.NoError(t, err)
+			_, err = f.WriteAt([]byte{'x'}, int64(tc.corruptID%4*recordSize+diskQueueHeaderSize))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			_, err = q.Dequeue()
+			assert.Equal(t, errDiskQueueCorrupted, err)
+			assert.Equal(t, len(tc.wantIDs), q.Len())
+			for _, id := range tc.wantIDs {
+				item, err := q.Dequeue()
+				require.NoError(t, err)
+				assert.Equal(t, id, item.ID)
+			}
+			_, err = q.Dequeue()
+			assert.Equal(t, ErrEmptyQueue, err)
+			enqueueDiskItems(t, q, 100, 101)
+			require.NoError(t, q.Close())
+
+			q = newTestDiskQueue(t, dir, 128)
+			assert.Equal(t, 1, q.Len())
+			dequeueDiskItems(t, q, 100, 101)
+			require.NoError(t, q.Close())
+		})
+	}
+}
@@ -1,5 +1,17 @@
 package queue
 
-import "github.com/WeiXinao/xkit/internal/queue"
+import (
+	"errors"
 
-var ErrOutOfCapacity = queue.ErrOutOfCapacity
+	"github.com/WeiXinao/xkit/internal/queue"
+)
+
+var (
+	ErrOutOfCapacity = queue.ErrOutOfCapacity
+	ErrEmptyQueue    = queue.ErrEmptyQueue
+)
+
+var (
+	ErrQueueClosed     = errors.New("xkit: 队列已经关闭")
+	errInvalidCapacity = errors.New("xkit: 容量必须大于 0")
+)
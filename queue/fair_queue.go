@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errFairQueueInvalidWeight = errors.New("xkit: 权重必须大于 0")
+
+// fairTenant 是某一个租户的队列
+// 所有有元素的租户组成一个环形链表，按照加权轮询的方式被服务
+type fairTenant[K comparable, T any] struct {
+	key  K
+	data []T
+	head int
+	// credit 是这一轮还可以取出的元素数量
+	credit     int
+	prev, next *fairTenant[K, T]
+}
+
+func (t *fairTenant[K, T]) len() int {
+	return len(t.data) - t.head
+}
+
+func (t *fairTenant[K, T]) pop() T {
+	val := t.data[t.head]
+	var zero T
+	t.data[t.head] = zero
+	t.head++
+	// 取出了一半以上，回收前面的空间
+	if t.head > len(t.data)/2 {
+		n := copy(t.data, t.data[t.head:])
+		t.data = t.data[:n]
+		t.head = 0
+	}
+	return val
+}
+
+// FairQueue 是多租户的公平队列
+// 元素按照租户放入各自的队列，出队的时候按照加权轮询在租户之间轮转：
+// 每一轮中，权重为 w 的租户最多取出 w 个元素，然后轮到下一个租户，
+// 因此单个租户放入再多的元素，也不会让其它租户饿死。同一个租户内部遵循 FIFO
+// 没有设置权重的租户，权重为 1
+type FairQueue[K comparable, T any] struct {
+	mutex    sync.Mutex
+	notEmpty *cond
+	// tenantCapacity 是单个租户的容量，<= 0 的时候不限制
+	tenantCapacity int
+	tenants        map[K]*fairTenant[K, T]
+	weights        map[K]int
+	// current 是正在被服务的租户，nil 表示没有任何元素
+	current *fairTenant[K, T]
+	length  int
+}
+
+// NewFairQueue 创建公平队列，tenantCapacity 是单个租户的容量，<= 0 的时候不限制
+func NewFairQueue[K comparable, T any](tenantCapacity int) *FairQueue[K, T] {
+	q := &FairQueue[K, T]{
+		tenantCapacity: tenantCapacity,
+		tenants:        make(map[K]*fairTenant[K, T]),
+		weights:        make(map[K]int),
+	}
+	q.notEmpty = newCond(&q.mutex)
+	return q
+}
+
+// SetWeight 设置租户的权重，从租户的下一轮开始生效
+func (q *FairQueue[K, T]) SetWeight(key K, weight int) error {
+	if weight <= 0 {
+		return errFairQueueInvalidWeight
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.weights[key] = weight
+	return nil
+}
+
+// Weight 返回租户的权重
+func (q *FairQueue[K, T]) Weight(key K) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.weight(key)
+}
+
+func (q *FairQueue[K, T]) weight(key K) int {
+	if w, ok := q.weights[key]; ok {
+		return w
+	}
+	return 1
+}
+
+// Enqueue 将元素放入租户的队列，租户的队列满了的时候返回 ErrOutOfCapacity
+func (q *FairQueue[K, T]) Enqueue(key K, t T) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	tenant, ok := q.tenants[key]
+	if !ok {
+		tenant = &fairTenant[K, T]{key: key}
+		q.tenants[key] = tenant
+		q.link(tenant)
+	}
+	if q.tenantCapacity > 0 && tenant.len() >= q.tenantCapacity {
+		return ErrOutOfCapacity
+	}
+	tenant.data = append(tenant.data, t)
+	q.length++
+	q.notEmpty.Broadcast()
+	return nil
+}
+
+// Dequeue 按照加权轮询取出元素，返回元素所属的租户
+// 队列为空的时候会阻塞，直到有元素或者 ctx 被取消
+func (q *FairQueue[K, T]) Dequeue(ctx context.Context) (K, T, error) {
+	var key K
+	var t T
+	if ctx.Err() != nil {
+		return key, t, ctx.Err()
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for q.length == 0 {
+		if err := q.notEmpty.Wait(ctx); err != nil {
+			return key, t, err
+		}
+	}
+	tenant := q.current
+	if tenant.credit <= 0 {
+		tenant.credit = q.weight(tenant.key)
+	}
+	t = tenant.pop()
+	tenant.credit--
+	q.length--
+	if tenant.len() == 0 {
+		// 租户没有元素了，移出轮转，下次放入元素的时候重新开始计算
+		q.unlink(tenant)
+		delete(q.tenants, tenant.key)
+	} else if tenant.credit == 0 {
+		q.current = tenant.next
+	}
+	return tenant.key, t, nil
+}
+
+// Len 返回所有租户的元素总数
+func (q *FairQueue[K, T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.length
+}
+
+// TenantLen 返回某个租户的元素数量
+func (q *FairQueue[K, T]) TenantLen(key K) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if tenant, ok := q.tenants[key]; ok {
+		return tenant.len()
+	}
+	return 0
+}
+
+// link 将租户放到轮转的末尾，也就是 current 的前面
+func (q *FairQueue[K, T]) link(tenant *fairTenant[K, T]) {
+	if q.current == nil {
+		tenant.prev, tenant.next = tenant, tenant
+		q.current = tenant
+		return
+	}
+	tail := q.current.prev
+	tenant.prev, tenant.next = tail, q.current
+	tail.next = tenant
+	q.current.prev = tenant
+}
+
+func (q *FairQueue[K, T]) unlink(tenant *fairTenant[K, T]) {
+	if tenant.next == tenant {
+		q.current = nil
+	} else {
+		tenant.prev.next = tenant.next
+		tenant.next.prev = tenant.prev
+		if q.current == tenant {
+			q.current = tenant.next
+		}
+	}
+	tenant.prev, tenant.next = nil, nil
+}
@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dequeueFairQueue(t *testing.T, q *FairQueue[string, string], n int) []string {
+	res := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		key, val, err := q.Dequeue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, key, val[:1])
+		res = append(res, val)
+	}
+	return res
+}
+
+func TestFairQueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		weights map[string]int
+		items   []string
+		want    []string
+	}{
+		{
+			name:  "默认权重",
+			items: []string{"a1", "a2", "a3", "b1", "c1", "c2"},
+			want:  []string{"a1", "b1", "c1", "a2", "c2", "a3"},
+		},
+		{
+			name:    "加权",
+			weights: map[string]int{"a": 2},
+			items:   []string{"a1", "a2", "a3", "a4", "b1", "b2", "b3", "c1"},
+			want:    []string{"a1", "a2", "b1", "c1", "a3", "a4", "b2", "b3"},
+		},
+		{
+			name:  "单个租户",
+			items: []string{"a1", "a2", "a3"},
+			want:  []string{"a1", "a2", "a3"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewFairQueue[string, string](0)
+			for k, w := range tc.weights {
+				require.NoError(t, q.SetWeight(k, w))
+			}
+			for _, item := range tc.items {
+				require.NoError(t, q.Enqueue(item[:1], item))
+			}
+			assert.Equal(t, len(tc.items), q.Len())
+			assert.Equal(t, tc.want, dequeueFairQueue(t, q, len(tc.items)))
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
+
+// 一个租户放入了大量元素，其它租户依旧可以按照权重被服务
+func TestFairQueue_NoisyTenant(t *testing.T) {
+	q := NewFairQueue[string, string](0)
+	require.NoError(t, q.SetWeight("b", 3))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, q.Enqueue("a", fmt.Sprintf("a%d", i)))
+	}
+	for i := 0; i < 30; i++ {
+		require.NoError(t, q.Enqueue("b", fmt.Sprintf("b%d", i)))
+	}
+	cnt := map[string]int{}
+	for _, val := range dequeueFairQueue(t, q, 40) {
+		cnt[val[:1]]++
+	}
+	assert.Equal(t, map[string]int{"a": 10, "b": 30}, cnt)
+	assert.Equal(t, 990, q.TenantLen("a"))
+	assert.Equal(t, 0, q.TenantLen("b"))
+}
+
+func TestFairQueue_Weight(t *testing.T) {
+	q := NewFairQueue[string, string](0)
+	assert.Equal(t, errFairQueueInvalidWeight, q.SetWeight("a", 0))
+	assert.Equal(t, 1, q.Weight("a"))
+	for i := 0; i < 6; i++ {
+		require.NoError(t, q.Enqueue("a", fmt.Sprintf("a%d", i)))
+		require.NoError(t, q.Enqueue("b", fmt.Sprintf("b%d", i)))
+	}
+	assert.Equal(t, []string{"a0", "b0"}, dequeueFairQueue(t, q, 2))
+	// 修改权重在下一轮生效
+	require.NoError(t, q.SetWeight("a", 3))
+	assert.Equal(t, 3, q.Weight("a"))
+	assert.Equal(t, []string{"a1", "a2", "a3", "b1", "a4", "a5", "b2"}, dequeueFairQueue(t, q, 7))
+}
+
+func TestFairQueue_Capacity(t *testing.T) {
+	q := NewFairQueue[string, string](2)
+	require.NoError(t, q.Enqueue("a", "a1"))
+	require.NoError(t, q.Enqueue("a", "a2"))
+	assert.Equal(t, ErrOutOfCapacity, q.Enqueue("a", "a3"))
+	// 容量是按照租户计算的
+	require.NoError(t, q.Enqueue("b", "b1"))
+	assert.Equal(t, []string{"a1"}, dequeueFairQueue(t, q, 1))
+	require.NoError(t, q.Enqueue("a", "a3"))
+	assert.Equal(t, 3, q.Len())
+}
+
+func TestFairQueue_Block(t *testing.T) {
+	q := NewFairQueue[string, string](0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := q.Dequeue(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue("a", "a1")
+	}()
+	assert.Equal(t, []string{"a1"}, dequeueFairQueue(t, q, 1))
+}
@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"errors"
+
+	"github.com/WeiXinao/xkit"
+)
+
+var errIndexedPriorityQueueKeyExists = errors.New("xkit: 键已经存在于优先队列中")
+
+type indexedEntry[K comparable, T any] struct {
+	key K
+	val T
+}
+
+// IndexedPriorityQueue 是带索引的优先队列
+// 每个元素都有一个唯一的键，队列记录了每个键在堆中的位置，
+// 因此可以在 O(log n) 内修改某个键的优先级或者删除某个键，而不需要重建堆
+// IndexedPriorityQueue 不是并发安全的
+type IndexedPriorityQueue[K comparable, T any] struct {
+	compare  xkit.Comparator[T]
+	capacity int
+	data     []indexedEntry[K, T]
+	// index 是键在 data 中的下标
+	index map[K]int
+}
+
+// NewIndexedPriorityQueue 创建带索引的优先队列 capacity <= 0 时，为无界队列
+func NewIndexedPriorityQueue[K comparable, T any](capacity int, compare xkit.Comparator[T]) *IndexedPriorityQueue[K, T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &IndexedPriorityQueue[K, T]{
+		compare:  compare,
+		capacity: capacity,
+		data:     make([]indexedEntry[K, T], 0, capacity),
+		index:    make(map[K]int, capacity),
+	}
+}
+
+func (p *IndexedPriorityQueue[K, T]) Len() int {
+	return len(p.data)
+}
+
+// Cap 无界队列返回 0，有界队列返回创建队列时设置的值
+func (p *IndexedPriorityQueue[K, T]) Cap() int {
+	return p.capacity
+}
+
+// Enqueue 放入键 k 以及它对应的元素
+// 如果 k 已经存在，那么返回错误，修改已有的元素应该使用 Update
+func (p *IndexedPriorityQueue[K, T]) Enqueue(k K, t T) error {
+	if _, ok := p.index[k]; ok {
+		return errIndexedPriorityQueueKeyExists
+	}
+	if p.capacity > 0 && len(p.data) >= p.capacity {
+		return ErrOutOfCapacity
+	}
+	p.data = append(p.data, indexedEntry[K, T]{key: k, val: t})
+	p.index[k] = len(p.data) - 1
+	p.up(len(p.data) - 1)
+	return nil
+}
+
+// Peek 返回优先级最高的键和元素，但是不会取出
+func (p *IndexedPriorityQueue[K, T]) Peek() (K, T, error) {
+	if len(p.data) == 0 {
+		var k K
+		var t T
+		return k, t, ErrEmptyQueue
+	}
+	return p.data[0].key, p.data[0].val, nil
+}
+
+// Dequeue 取出优先级最高的键和元素
+func (p *IndexedPriorityQueue[K, T]) Dequeue() (K, T, error) {
+	if len(p.data) == 0 {
+		var k K
+		var t T
+		return k, t, ErrEmptyQueue
+	}
+	e := p.removeAt(0)
+	return e.key, e.val, nil
+}
+
+// Contains 判断 k 是否在队列中
+func (p *IndexedPriorityQueue[K, T]) Contains(k K) bool {
+	_, ok := p.index[k]
+	return ok
+}
+
+// Get 返回 k 对应的元素
+func (p *IndexedPriorityQueue[K, T]) Get(k K) (T, bool) {
+	i, ok := p.index[k]
+	if !ok {
+		var t T
+		return t, false
+	}
+	return p.data[i].val, true
+}
+
+// Update 修改 k 对应的元素，并且根据新的优先级调整它在堆中的位置
+// 如果 k 不存在，那么返回 false
+func (p *IndexedPriorityQueue[K, T]) Update(k K, t T) bool {
+	i, ok := p.index[k]
+	if !ok {
+		return false
+	}
+	p.data[i].val = t
+	p.fix(i)
+	return true
+}
+
+// Remove 删除 k 以及它对应的元素
+func (p *IndexedPriorityQueue[K, T]) Remove(k K) (T, bool) {
+	i, ok := p.index[k]
+	if !ok {
+		var t T
+		return t, false
+	}
+	return p.removeAt(i).val, true
+}
+
+func (p *IndexedPriorityQueue[K, T]) removeAt(i int) indexedEntry[K, T] {
+	e := p.data[i]
+	last := len(p.data) - 1
+	if i != last {
+		p.swap(i, last)
+	}
+	p.data[last] = indexedEntry[K, T]{}
+	p.data = p.data[:last]
+	delete(p.index, e.key)
+	if i != last {
+		p.fix(i)
+	}
+	return e
+}
+
+// fix 在 i 的元素发生变化之后恢复堆的性质
+func (p *IndexedPriorityQueue[K, T]) fix(i int) {
+	if !p.down(i) {
+		p.up(i)
+	}
+}
+
+func (p *IndexedPriorityQueue[K, T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if p.compare(p.data[i].val, p.data[parent].val) >= 0 {
+			break
+		}
+		p.swap(i, parent)
+		i = parent
+	}
+}
+
+// down 返回元素是否向下移动了
+func (p *IndexedPriorityQueue[K, T]) down(i int) bool {
+	start, n := i, len(p.data)
+	for {
+		minPos := i
+		if left := 2*i + 1; left < n && p.compare(p.data[left].val, p.data[minPos].val) < 0 {
+			minPos = left
+		}
+		if right := 2*i + 2; right < n && p.compare(p.data[right].val, p.data[minPos].val) < 0 {
+			minPos = right
+		}
+		if minPos == i {
+			break
+		}
+		p.swap(i, minPos)
+		i = minPos
+	}
+	return i > start
+}
+
+func (p *IndexedPriorityQueue[K, T]) swap(i, j int) {
+	p.data[i], p.data[j] = p.data[j], p.data[i]
+	p.index[p.data[i].key] = i
+	p.index[p.data[j].key] = j
+}
@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	q := NewIndexedPriorityQueue[string, int](3, xkit.ComparatorRealNumber[int])
+	assert.Equal(t, 3, q.Cap())
+	_, _, err := q.Peek()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, _, err = q.Dequeue()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	require.NoError(t, q.Enqueue("a", 3))
+	require.NoError(t, q.Enqueue("b", 1))
+	assert.Equal(t, errIndexedPriorityQueueKeyExists, q.Enqueue("a", 0))
+	require.NoError(t, q.Enqueue("c", 2))
+	assert.Equal(t, ErrOutOfCapacity, q.Enqueue("d", 0))
+	assert.Equal(t, 3, q.Len())
+
+	assert.True(t, q.Contains("a"))
+	assert.False(t, q.Contains("d"))
+	val, ok := q.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, val)
+	_, ok = q.Get("d")
+	assert.False(t, ok)
+
+	k, val, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "b", k)
+	assert.Equal(t, 1, val)
+
+	// 提高 a 的优先级
+	assert.True(t, q.Update("a", 0))
+	assert.False(t, q.Update("d", 0))
+	k, _, _ = q.Peek()
+	assert.Equal(t, "a", k)
+	// 降低 a 的优先级
+	assert.True(t, q.Update("a", 10))
+	k, _, _ = q.Peek()
+	assert.Equal(t, "b", k)
+
+	val, ok = q.Remove("b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	_, ok = q.Remove("b")
+	assert.False(t, ok)
+
+	k, val, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, "c", k)
+	assert.Equal(t, 2, val)
+	k, val, err = q.Dequeue()
+	require.NoError(t, err)
+	assert.Equal(t, "a", k)
+	assert.Equal(t, 10, val)
+	assert.Equal(t, 0, q.Len())
+}
+
+// 随机修改和删除，和排序的结果对比，并且检查索引和堆是否一致
+func TestIndexedPriorityQueue_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := NewIndexedPriorityQueue[int, int](0, xkit.ComparatorRealNumber[int])
+	want := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(200)
+		switch r.Intn(3) {
+		case 0:
+			if _, ok := want[k]; ok {
+				assert.True(t, q.Update(k, r.Intn(1000)))
+				want[k], _ = q.Get(k)
+			} else {
+				want[k] = r.Intn(1000)
+				require.NoError(t, q.Enqueue(k, want[k]))
+			}
+		case 1:
+			_, ok := q.Remove(k)
+			_, wantOk := want[k]
+			assert.Equal(t, wantOk, ok)
+			delete(want, k)
+		default:
+			if _, ok := want[k]; ok {
+				q.Update(k, -r.Intn(1000))
+				want[k], _ = q.Get(k)
+			}
+		}
+		for idx, e := range q.data {
+			assert.Equal(t, idx, q.index[e.key])
+		}
+	}
+	assert.Equal(t, len(want), q.Len())
+	vals := make([]int, 0, len(want))
+	for _, v := range want {
+		vals = append(vals, v)
+	}
+	sort.Ints(vals)
+	res := make([]int, 0, len(want))
+	for q.Len() > 0 {
+		k, val, err := q.Dequeue()
+		require.NoError(t, err)
+		assert.Equal(t, want[k], val)
+		res = append(res, val)
+	}
+	assert.Equal(t, vals, res)
+}
@@ -0,0 +1,159 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type linkedNode[T any] struct {
+	val  T
+	next *linkedNode[T]
+}
+
+// LinkedBlockingQueue 是基于链表的阻塞队列，遵循 FIFO，可以是有界的也可以是无界的
+// 入队和出队分别使用两把锁，生产者和消费者之间不会互相竞争
+type LinkedBlockingQueue[T any] struct {
+	// head 是哨兵节点，head.next 才是队首元素
+	head *linkedNode[T]
+	tail *linkedNode[T]
+	// capacity <= 0 的时候是无界队列
+	capacity int
+	count    atomic.Int64
+
+	// takeLock 保护 head，putLock 保护 tail
+	takeLock sync.Mutex
+	notEmpty *cond
+	putLock  sync.Mutex
+	notFull  *cond
+}
+
+// NewLinkedBlockingQueue 创建一个 LinkedBlockingQueue，capacity <= 0 时，为无界队列
+func NewLinkedBlockingQueue[T any](capacity int) *LinkedBlockingQueue[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	dummy := &linkedNode[T]{}
+	q := &LinkedBlockingQueue[T]{
+		head:     dummy,
+		tail:     dummy,
+		capacity: capacity,
+	}
+	q.notEmpty = newCond(&q.takeLock)
+	q.notFull = newCond(&q.putLock)
+	return q
+}
+
+func (q *LinkedBlockingQueue[T]) Enqueue(ctx context.Context, t T) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	q.putLock.Lock()
+	for q.isFull() {
+		if err := q.notFull.Wait(ctx); err != nil {
+			q.putLock.Unlock()
+			return err
+		}
+	}
+	node := &linkedNode[T]{val: t}
+	q.tail.next = node
+	q.tail = node
+	c := q.count.Add(1)
+	// 还有空位，通知其它生产者
+	if !q.isFull() {
+		q.notFull.Signal()
+	}
+	q.putLock.Unlock()
+	// 从空变为非空，消费者可能在等待
+	if c == 1 {
+		q.signalNotEmpty()
+	}
+	return nil
+}
+
+func (q *LinkedBlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	if ctx.Err() != nil {
+		var t T
+		return t, ctx.Err()
+	}
+	q.takeLock.Lock()
+	for q.count.Load() == 0 {
+		if err := q.notEmpty.Wait(ctx); err != nil {
+			q.takeLock.Unlock()
+			var t T
+			return t, err
+		}
+	}
+	t := q.dequeue()
+	c := q.count.Add(-1)
+	// 还有元素，通知其它消费者
+	if c > 0 {
+		q.notEmpty.Signal()
+	}
+	q.takeLock.Unlock()
+	// 从满变为不满，生产者可能在等待
+	if q.capacity > 0 && c == int64(q.capacity)-1 {
+		q.signalNotFull()
+	}
+	return t, nil
+}
+
+// DrainTo 取出最多 n 个元素，不会阻塞。n <= 0 的时候取出所有元素
+func (q *LinkedBlockingQueue[T]) DrainTo(n int) []T {
+	q.takeLock.Lock()
+	cnt := int(q.count.Load())
+	if n <= 0 || n > cnt {
+		n = cnt
+	}
+	res := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, q.dequeue())
+	}
+	c := q.count.Add(int64(-n))
+	if c > 0 {
+		q.notEmpty.Signal()
+	}
+	q.takeLock.Unlock()
+	if n > 0 && q.capacity > 0 {
+		q.signalNotFull()
+	}
+	return res
+}
+
+// dequeue 取出队首元素，调用者需要持有 takeLock 并且确保队列不为空
+// 原本的队首节点会成为新的哨兵节点
+func (q *LinkedBlockingQueue[T]) dequeue() T {
+	first := q.head.next
+	// 断开旧的哨兵节点，帮助 GC
+	q.head.next = nil
+	q.head = first
+	t := first.val
+	var zero T
+	first.val = zero
+	return t
+}
+
+func (q *LinkedBlockingQueue[T]) isFull() bool {
+	return q.capacity > 0 && q.count.Load() >= int64(q.capacity)
+}
+
+func (q *LinkedBlockingQueue[T]) signalNotEmpty() {
+	q.takeLock.Lock()
+	defer q.takeLock.Unlock()
+	q.notEmpty.Signal()
+}
+
+func (q *LinkedBlockingQueue[T]) signalNotFull() {
+	q.putLock.Lock()
+	defer q.putLock.Unlock()
+	q.notFull.Signal()
+}
+
+func (q *LinkedBlockingQueue[T]) Len() int {
+	return int(q.count.Load())
+}
+
+// Cap 无界队列返回 0，有界队列返回创建队列时设置的值
+func (q *LinkedBlockingQueue[T]) Cap() int {
+	return q.capacity
+}
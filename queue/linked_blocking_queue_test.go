@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 验证一下 LinkedBlockingQueue 实现了 BlockingQueue 接口
+var _ BlockingQueue[int] = &LinkedBlockingQueue[int]{}
+
+func TestLinkedBlockingQueue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		wantCap  int
+		wantErr  error
+	}{
+		{
+			name:     "无界",
+			capacity: -1,
+			wantCap:  0,
+		},
+		{
+			name:     "有界",
+			capacity: 3,
+			wantCap:  3,
+			wantErr:  context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewLinkedBlockingQueue[int](tc.capacity)
+			assert.Equal(t, tc.wantCap, q.Cap())
+			for i := 0; i < 3; i++ {
+				require.NoError(t, q.Enqueue(context.Background(), i))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			assert.Equal(t, tc.wantErr, q.Enqueue(ctx, 3))
+
+			val, err := q.Dequeue(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 0, val)
+			assert.Equal(t, []int{1, 2}, q.DrainTo(2))
+			q.DrainTo(0)
+			assert.Equal(t, 0, q.Len())
+
+			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err = q.Dequeue(ctx)
+			assert.Equal(t, context.DeadlineExceeded, err)
+		})
+	}
+}
+
+func TestLinkedBlockingQueue_Block(t *testing.T) {
+	q := NewLinkedBlockingQueue[int](1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = q.Enqueue(context.Background(), 1)
+	}()
+	// 被阻塞，直到上面的 goroutine 放入了元素
+	val, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	require.NoError(t, q.Enqueue(context.Background(), 2))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.DrainTo(0)
+	}()
+	require.NoError(t, q.Enqueue(context.Background(), 3))
+	assert.Equal(t, []int{3}, q.DrainTo(0))
+}
+
+func TestLinkedBlockingQueue_Concurrent(t *testing.T) {
+	testBlockingQueueConcurrent(t, NewLinkedBlockingQueue[int](4))
+	testBlockingQueueConcurrent(t, NewLinkedBlockingQueue[int](0))
+}
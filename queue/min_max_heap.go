@@ -0,0 +1,195 @@
+package queue
+
+import (
+	"math/bits"
+
+	"github.com/WeiXinao/xkit"
+)
+
+// MinMaxHeap 是双端优先队列，可以在 O(log n) 内取出最小元素或者最大元素
+// 偶数层（根节点在第 0 层）上的节点不大于它所有的子孙节点，奇数层上的节点不小于它所有的子孙节点
+// 有界的时候，放入元素超过容量会淘汰掉最大的元素，因此可以直接用来维护流式数据中最小的 K 个元素
+// MinMaxHeap 不是并发安全的
+type MinMaxHeap[T any] struct {
+	compare  xkit.Comparator[T]
+	capacity int
+	data     []T
+}
+
+// NewMinMaxHeap 创建一个 MinMaxHeap capacity <= 0 时，为无界队列
+func NewMinMaxHeap[T any](capacity int, compare xkit.Comparator[T]) *MinMaxHeap[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &MinMaxHeap[T]{
+		compare:  compare,
+		capacity: capacity,
+		data:     make([]T, 0, capacity),
+	}
+}
+
+func (h *MinMaxHeap[T]) Len() int {
+	return len(h.data)
+}
+
+// Cap 无界队列返回 0，有界队列返回创建队列时设置的值
+func (h *MinMaxHeap[T]) Cap() int {
+	return h.capacity
+}
+
+// Push 放入元素
+// 如果队列已经满了，那么 t 和队列中最大的元素里面较大的那个会被淘汰，返回被淘汰的元素和 true
+func (h *MinMaxHeap[T]) Push(t T) (T, bool) {
+	if h.capacity > 0 && len(h.data) >= h.capacity {
+		maxIdx := h.maxIndex()
+		if h.compare(t, h.data[maxIdx]) >= 0 {
+			return t, true
+		}
+		evicted := h.removeAt(maxIdx)
+		h.push(t)
+		return evicted, true
+	}
+	h.push(t)
+	var zero T
+	return zero, false
+}
+
+func (h *MinMaxHeap[T]) PeekMin() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.data[0], nil
+}
+
+func (h *MinMaxHeap[T]) PeekMax() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.data[h.maxIndex()], nil
+}
+
+func (h *MinMaxHeap[T]) PopMin() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.removeAt(0), nil
+}
+
+func (h *MinMaxHeap[T]) PopMax() (T, error) {
+	if len(h.data) == 0 {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.removeAt(h.maxIndex()), nil
+}
+
+// maxIndex 返回最大元素的下标，最大元素一定是根节点或者根节点的某个子节点
+func (h *MinMaxHeap[T]) maxIndex() int {
+	switch len(h.data) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.compare(h.data[1], h.data[2]) >= 0 {
+		return 1
+	}
+	return 2
+}
+
+func (h *MinMaxHeap[T]) push(t T) {
+	h.data = append(h.data, t)
+	i := len(h.data) - 1
+	if i == 0 {
+		return
+	}
+	parent := (i - 1) / 2
+	if isMinLevel(i) {
+		if h.compare(h.data[i], h.data[parent]) > 0 {
+			h.swap(i, parent)
+			h.bubbleUp(parent, 1)
+		} else {
+			h.bubbleUp(i, -1)
+		}
+		return
+	}
+	if h.compare(h.data[i], h.data[parent]) < 0 {
+		h.swap(i, parent)
+		h.bubbleUp(parent, -1)
+	} else {
+		h.bubbleUp(i, 1)
+	}
+}
+
+func (h *MinMaxHeap[T]) removeAt(i int) T {
+	t := h.data[i]
+	last := len(h.data) - 1
+	h.data[i] = h.data[last]
+	var zero T
+	h.data[last] = zero
+	h.data = h.data[:last]
+	if i < last {
+		h.trickleDown(i)
+	}
+	return t
+}
+
+// bubbleUp 沿着祖父节点向上调整
+// sign 为 -1 的时候是在最小层上调整，为 1 的时候是在最大层上调整
+func (h *MinMaxHeap[T]) bubbleUp(i int, sign int) {
+	for i > 2 {
+		grandparent := ((i-1)/2 - 1) / 2
+		if h.compare(h.data[i], h.data[grandparent])*sign <= 0 {
+			break
+		}
+		h.swap(i, grandparent)
+		i = grandparent
+	}
+}
+
+func (h *MinMaxHeap[T]) trickleDown(i int) {
+	sign := 1
+	if isMinLevel(i) {
+		sign = -1
+	}
+	n := len(h.data)
+	for {
+		// 在子节点和孙节点中找到最小（或者最大）的那个
+		m, firstChild := i, 2*i+1
+		if firstChild >= n {
+			return
+		}
+		candidates := [6]int{firstChild, firstChild + 1,
+			2*firstChild + 1, 2*firstChild + 2, 2*firstChild + 3, 2*firstChild + 4}
+		for _, c := range candidates {
+			if c < n && h.compare(h.data[c], h.data[m])*sign > 0 {
+				m = c
+			}
+		}
+		if m == i {
+			return
+		}
+		h.swap(i, m)
+		if m <= firstChild+1 {
+			// 子节点没有子孙节点需要调整
+			return
+		}
+		parent := (m - 1) / 2
+		if h.compare(h.data[m], h.data[parent])*sign < 0 {
+			h.swap(m, parent)
+		}
+		i = m
+	}
+}
+
+func (h *MinMaxHeap[T]) swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+}
+
+// isMinLevel 判断下标 i 是否在最小层上
+func isMinLevel(i int) bool {
+	return (bits.Len(uint(i+1))-1)%2 == 0
+}
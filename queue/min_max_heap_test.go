@@ -0,0 +1,149 @@
+package queue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMaxHeap(t *testing.T) {
+	h := NewMinMaxHeap[int](0, xkit.ComparatorRealNumber[int])
+	assert.Equal(t, 0, h.Cap())
+	for _, fn := range []func() (int, error){h.PeekMin, h.PeekMax, h.PopMin, h.PopMax} {
+		_, err := fn()
+		assert.Equal(t, ErrEmptyQueue, err)
+	}
+
+	for _, val := range []int{5, 3, 8, 1, 9, 2, 7} {
+		_, evicted := h.Push(val)
+		assert.False(t, evicted)
+	}
+	assert.Equal(t, 7, h.Len())
+	val, err := h.PeekMin()
+	require.NoError(t, err)
+	assert.Equal(t, 1, val)
+	val, err = h.PeekMax()
+	require.NoError(t, err)
+	assert.Equal(t, 9, val)
+
+	res := make([]int, 0, 7)
+	for h.Len() > 0 {
+		minVal, err := h.PopMin()
+		require.NoError(t, err)
+		res = append(res, minVal)
+		if h.Len() == 0 {
+			break
+		}
+		maxVal, err := h.PopMax()
+		require.NoError(t, err)
+		res = append(res, maxVal)
+	}
+	assert.Equal(t, []int{1, 9, 2, 8, 3, 7, 5}, res)
+}
+
+func TestMinMaxHeap_Bounded(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        []int
+		push        int
+		wantEvicted int
+		wantOk      bool
+		wantSorted  []int
+	}{
+		{
+			name:       "没有满",
+			data:       []int{3, 1},
+			push:       2,
+			wantSorted: []int{1, 2, 3},
+		},
+		{
+			name:        "淘汰队列中最大的元素",
+			data:        []int{3, 1, 5},
+			push:        2,
+			wantEvicted: 5,
+			wantOk:      true,
+			wantSorted:  []int{1, 2, 3},
+		},
+		{
+			name:        "淘汰新元素",
+			data:        []int{3, 1, 5},
+			push:        6,
+			wantEvicted: 6,
+			wantOk:      true,
+			wantSorted:  []int{1, 3, 5},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMinMaxHeap[int](3, xkit.ComparatorRealNumber[int])
+			for _, val := range tc.data {
+				h.Push(val)
+			}
+			evicted, ok := h.Push(tc.push)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantEvicted, evicted)
+			res := make([]int, 0, h.Len())
+			for h.Len() > 0 {
+				val, _ := h.PopMin()
+				res = append(res, val)
+			}
+			assert.Equal(t, tc.wantSorted, res)
+		})
+	}
+}
+
+// 流式数据中的最小 K 个元素
+func TestMinMaxHeap_TopK(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const k = 10
+	h := NewMinMaxHeap[int](k, xkit.ComparatorRealNumber[int])
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = r.Intn(10000)
+		h.Push(data[i])
+	}
+	sort.Ints(data)
+	res := make([]int, 0, k)
+	for h.Len() > 0 {
+		val, _ := h.PopMin()
+		res = append(res, val)
+	}
+	assert.Equal(t, data[:k], res)
+}
+
+// 随机操作，与排序后的切片对比
+func TestMinMaxHeap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := NewMinMaxHeap[int](0, xkit.ComparatorRealNumber[int])
+	var want []int
+	for i := 0; i < 5000; i++ {
+		switch r.Intn(4) {
+		case 0:
+			val, err := h.PopMin()
+			if len(want) == 0 {
+				assert.Equal(t, ErrEmptyQueue, err)
+				continue
+			}
+			assert.Equal(t, want[0], val)
+			want = want[1:]
+		case 1:
+			val, err := h.PopMax()
+			if len(want) == 0 {
+				assert.Equal(t, ErrEmptyQueue, err)
+				continue
+			}
+			assert.Equal(t, want[len(want)-1], val)
+			want = want[:len(want)-1]
+		default:
+			val := r.Intn(100)
+			h.Push(val)
+			want = append(want, val)
+			sort.Ints(want)
+		}
+		assert.Equal(t, len(want), h.Len())
+	}
+}
@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"errors"
+
+	"github.com/WeiXinao/xkit"
+)
+
+var (
+	errPairingHeapInvalidHandle = errors.New("xkit: 句柄不属于这个堆，或者元素已经被删除")
+	errPairingHeapIncreaseKey   = errors.New("xkit: DecreaseKey 的新值不能大于原本的值")
+)
+
+// PairingNode 是 PairingHeap 中的节点，作为句柄用于 DecreaseKey
+type PairingNode[T any] struct {
+	val T
+	// child 是第一个子节点，sibling 是下一个兄弟节点
+	child   *PairingNode[T]
+	sibling *PairingNode[T]
+	// prev 是前一个兄弟节点，如果是第一个子节点，那么是父节点
+	prev *PairingNode[T]
+	// owner 用于判断节点是否属于某个堆，被删除之后为 nil
+	owner *pairingHeapOwner
+}
+
+// Value 返回节点中的元素
+func (n *PairingNode[T]) Value() T {
+	return n.val
+}
+
+// pairingHeapOwner 标识节点所属的堆
+// Meld 的时候只需要修改被合并的堆的 owner 指向，不需要遍历所有节点
+type pairingHeapOwner struct {
+	// parent 不为 nil 说明这个堆已经被合并到了其它堆中
+	parent *pairingHeapOwner
+}
+
+func (o *pairingHeapOwner) find() *pairingHeapOwner {
+	for o.parent != nil {
+		if o.parent.parent != nil {
+			// 路径压缩
+			o.parent = o.parent.parent
+		}
+		o = o.parent
+	}
+	return o
+}
+
+// PairingHeap 是配对堆，一种可以高效合并的堆
+// Insert、FindMin、Meld 和 DecreaseKey 的均摊复杂度是 O(1)，DeleteMin 的均摊复杂度是 O(log n)
+// PairingHeap 不是并发安全的
+type PairingHeap[T any] struct {
+	compare xkit.Comparator[T]
+	root    *PairingNode[T]
+	length  int
+	owner   *pairingHeapOwner
+}
+
+func NewPairingHeap[T any](compare xkit.Comparator[T]) *PairingHeap[T] {
+	return &PairingHeap[T]{
+		compare: compare,
+		owner:   &pairingHeapOwner{},
+	}
+}
+
+func (h *PairingHeap[T]) Len() int {
+	return h.length
+}
+
+// Insert 放入元素，返回的节点可以用于 DecreaseKey
+func (h *PairingHeap[T]) Insert(t T) *PairingNode[T] {
+	n := &PairingNode[T]{val: t, owner: h.owner}
+	h.root = h.link(h.root, n)
+	h.length++
+	return n
+}
+
+// FindMin 返回最小的元素，但是不会删除
+func (h *PairingHeap[T]) FindMin() (T, error) {
+	if h.root == nil {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	return h.root.val, nil
+}
+
+// DeleteMin 删除并返回最小的元素
+func (h *PairingHeap[T]) DeleteMin() (T, error) {
+	if h.root == nil {
+		var t T
+		return t, ErrEmptyQueue
+	}
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	if h.root != nil {
+		h.root.prev = nil
+	}
+	h.length--
+	root.child, root.owner = nil, nil
+	return root.val, nil
+}
+
+// Meld 将 other 中所有的元素合并到 h 中，合并之后 other 为空
+// other 中节点的句柄依旧有效，可以在 h 上调用 DecreaseKey
+// 两个堆应该使用相同的比较器
+func (h *PairingHeap[T]) Meld(other *PairingHeap[T]) {
+	if other == h || other.root == nil {
+		return
+	}
+	h.root = h.link(h.root, other.root)
+	h.length += other.length
+	other.owner.parent = h.owner
+	other.root, other.length, other.owner = nil, 0, &pairingHeapOwner{}
+}
+
+// DecreaseKey 将节点中的元素修改为更小的 t
+func (h *PairingHeap[T]) DecreaseKey(n *PairingNode[T], t T) error {
+	if n == nil || n.owner == nil || n.owner.find() != h.owner {
+		return errPairingHeapInvalidHandle
+	}
+	if h.compare(t, n.val) > 0 {
+		return errPairingHeapIncreaseKey
+	}
+	n.val = t
+	if n == h.root {
+		return nil
+	}
+	// 从父节点上摘下来，再和根节点合并
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+	h.root = h.link(h.root, n)
+	return nil
+}
+
+// link 合并两棵树，较大的根节点成为较小的根节点的第一个子节点
+func (h *PairingHeap[T]) link(a, b *PairingNode[T]) *PairingNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.compare(b.val, a.val) < 0 {
+		a, b = b, a
+	}
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.sibling = nil
+	return a
+}
+
+// mergePairs 两两合并兄弟节点，然后从右往左依次合并
+func (h *PairingHeap[T]) mergePairs(first *PairingNode[T]) *PairingNode[T] {
+	var pairs []*PairingNode[T]
+	for first != nil {
+		a, b := first, first.sibling
+		if b == nil {
+			a.prev, a.sibling = nil, nil
+			pairs = append(pairs, a)
+			break
+		}
+		first = b.sibling
+		a.prev, a.sibling = nil, nil
+		b.prev, b.sibling = nil, nil
+		pairs = append(pairs, h.link(a, b))
+	}
+	var root *PairingNode[T]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = h.link(pairs[i], root)
+	}
+	return root
+}
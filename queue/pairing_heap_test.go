@@ -0,0 +1,145 @@
+package queue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drainPairingHeap(t *testing.T, h *PairingHeap[int]) []int {
+	res := make([]int, 0, h.Len())
+	for h.Len() > 0 {
+		minVal, err := h.FindMin()
+		require.NoError(t, err)
+		val, err := h.DeleteMin()
+		require.NoError(t, err)
+		assert.Equal(t, minVal, val)
+		res = append(res, val)
+	}
+	return res
+}
+
+func TestPairingHeap(t *testing.T) {
+	h := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	_, err := h.FindMin()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = h.DeleteMin()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	for _, val := range []int{5, 3, 8, 1, 9, 2, 7, 3} {
+		h.Insert(val)
+	}
+	assert.Equal(t, 8, h.Len())
+	assert.Equal(t, []int{1, 2, 3, 3, 5, 7, 8, 9}, drainPairingHeap(t, h))
+}
+
+func TestPairingHeap_DecreaseKey(t *testing.T) {
+	h := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	nodes := make([]*PairingNode[int], 0, 10)
+	for i := 10; i < 20; i++ {
+		nodes = append(nodes, h.Insert(i))
+	}
+	// 先删除一次，让树有更深的结构
+	val, err := h.DeleteMin()
+	require.NoError(t, err)
+	assert.Equal(t, 10, val)
+
+	assert.Equal(t, errPairingHeapInvalidHandle, h.DecreaseKey(nodes[0], 1))
+	assert.Equal(t, errPairingHeapIncreaseKey, h.DecreaseKey(nodes[5], 100))
+	require.NoError(t, h.DecreaseKey(nodes[5], 1))
+	assert.Equal(t, 1, nodes[5].Value())
+	require.NoError(t, h.DecreaseKey(nodes[9], 2))
+	// 根节点也可以减小
+	require.NoError(t, h.DecreaseKey(nodes[5], 0))
+	assert.Equal(t, []int{0, 2, 11, 12, 13, 14, 16, 17, 18}, drainPairingHeap(t, h))
+	assert.Equal(t, errPairingHeapInvalidHandle, h.DecreaseKey(nil, 0))
+}
+
+func TestPairingHeap_Meld(t *testing.T) {
+	a := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	b := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	c := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	a.Insert(5)
+	a.Insert(1)
+	nodeB := b.Insert(8)
+	b.Insert(3)
+	nodeC := c.Insert(9)
+
+	b.Meld(c)
+	a.Meld(b)
+	a.Meld(a)
+	a.Meld(NewPairingHeap[int](xkit.ComparatorRealNumber[int]))
+	assert.Equal(t, 5, a.Len())
+	assert.Equal(t, 0, b.Len())
+	assert.Equal(t, 0, c.Len())
+	_, err := b.FindMin()
+	assert.Equal(t, ErrEmptyQueue, err)
+
+	// 合并之后句柄属于新的堆
+	assert.Equal(t, errPairingHeapInvalidHandle, b.DecreaseKey(nodeB, 0))
+	assert.Equal(t, errPairingHeapInvalidHandle, c.DecreaseKey(nodeC, 0))
+	require.NoError(t, a.DecreaseKey(nodeC, 0))
+	require.NoError(t, a.DecreaseKey(nodeB, 2))
+
+	// 被合并的堆可以继续使用
+	b.Insert(4)
+	assert.Equal(t, []int{4}, drainPairingHeap(t, b))
+	assert.Equal(t, []int{0, 1, 2, 3, 5}, drainPairingHeap(t, a))
+}
+
+// 随机插入、删除、减小以及合并，与排序后的结果对比
+func TestPairingHeap_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+	var nodes []*PairingNode[int]
+	var want []int
+	for i := 0; i < 5000; i++ {
+		switch r.Intn(5) {
+		case 0:
+			val, err := h.DeleteMin()
+			if len(want) == 0 {
+				assert.Equal(t, ErrEmptyQueue, err)
+				continue
+			}
+			sort.Ints(want)
+			assert.Equal(t, want[0], val)
+			want = want[1:]
+		case 1:
+			if len(nodes) == 0 {
+				continue
+			}
+			n := nodes[r.Intn(len(nodes))]
+			old := n.Value()
+			err := h.DecreaseKey(n, old-r.Intn(100))
+			if err != nil {
+				// 已经被删除了
+				continue
+			}
+			for j, val := range want {
+				if val == old {
+					want[j] = n.Value()
+					break
+				}
+			}
+		case 2:
+			other := NewPairingHeap[int](xkit.ComparatorRealNumber[int])
+			for j := r.Intn(5); j > 0; j-- {
+				val := r.Intn(10000)
+				nodes = append(nodes, other.Insert(val))
+				want = append(want, val)
+			}
+			h.Meld(other)
+		default:
+			val := r.Intn(10000)
+			nodes = append(nodes, h.Insert(val))
+			want = append(want, val)
+		}
+		assert.Equal(t, len(want), h.Len())
+	}
+	sort.Ints(want)
+	assert.Equal(t, want, drainPairingHeap(t, h))
+}
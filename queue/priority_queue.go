@@ -30,3 +30,30 @@ func NewPriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *Priority
 	pq.priorityQueue = queue.NewPriorityQueue[T](capacity, compare)
 	return pq
 }
+
+// NewStablePriorityQueue 创建稳定的优先队列，优先级相同的元素按照入队的顺序出队
+func NewStablePriorityQueue[T any](capacity int, compare xkit.Comparator[T]) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		priorityQueue: queue.NewStablePriorityQueue[T](capacity, compare),
+	}
+}
+
+// Range 按照堆中的顺序遍历元素
+func (pq *PriorityQueue[T]) Range(fn func(index int, t T) error) error {
+	return pq.priorityQueue.Range(fn)
+}
+
+// AsSlice 按照堆中的顺序返回所有元素的副本
+func (pq *PriorityQueue[T]) AsSlice() []T {
+	return pq.priorityQueue.AsSlice()
+}
+
+// RangeSorted 按照出队的顺序遍历元素
+func (pq *PriorityQueue[T]) RangeSorted(fn func(index int, t T) error) error {
+	return pq.priorityQueue.RangeSorted(fn)
+}
+
+// AsSortedSlice 按照出队的顺序返回所有元素的副本
+func (pq *PriorityQueue[T]) AsSortedSlice() []T {
+	return pq.priorityQueue.AsSortedSlice()
+}
@@ -16,3 +16,24 @@ func ExampleNewPriorityQueue() {
 	// Output:
 	// 9
 }
+
+func ExampleNewStablePriorityQueue() {
+	type job struct {
+		priority int
+		name     string
+	}
+	pq := queue.NewStablePriorityQueue(0, func(src, dst job) int {
+		return xkit.ComparatorRealNumber(src.priority, dst.priority)
+	})
+	_ = pq.Enqueue(job{priority: 1, name: "first"})
+	_ = pq.Enqueue(job{priority: 0, name: "urgent"})
+	_ = pq.Enqueue(job{priority: 1, name: "second"})
+	for pq.Len() > 0 {
+		j, _ := pq.Dequeue()
+		fmt.Println(j.name)
+	}
+	// Output:
+	// urgent
+	// first
+	// second
+}
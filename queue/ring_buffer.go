@@ -0,0 +1,359 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+// cacheLineSize 是常见 CPU 的缓存行大小，用于填充，避免伪共享
+const cacheLineSize = 64
+
+type cacheLinePad [cacheLineSize]byte
+
+// ringSlot 是环形缓冲区的槽位
+// seq 是槽位的序号：等于位置 pos 的时候表示可以写入，等于 pos+1 的时候表示可以读取
+type ringSlot[T any] struct {
+	seq atomic.Uint64
+	val T
+}
+
+// ring 是三种环形缓冲区共用的部分
+// 生产者和消费者只通过槽位上的序号同步，读写位置各自独占缓存行
+type ring[T any] struct {
+	_     cacheLinePad
+	slots []ringSlot[T]
+	mask  uint64
+	_     cacheLinePad
+	// tail 是下一个写入的位置
+	tail atomic.Uint64
+	_    cacheLinePad
+	// head 是下一个读取的位置
+	head atomic.Uint64
+	_    cacheLinePad
+}
+
+func newRing[T any](capacity int) (*ring[T], error) {
+	if capacity <= 0 {
+		return nil, errInvalidCapacity
+	}
+	// 容量向上取整到 2 的幂，这样可以用位运算代替取模
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+	r := &ring[T]{
+		slots: make([]ringSlot[T], size),
+		mask:  uint64(size - 1),
+	}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+	return r, nil
+}
+
+// enqueueSingle 只有一个生产者的时候写入
+func (r *ring[T]) enqueueSingle(t T) bool {
+	pos := r.tail.Load()
+	slot := &r.slots[pos&r.mask]
+	if slot.seq.Load() != pos {
+		return false
+	}
+	slot.val = t
+	slot.seq.Store(pos + 1)
+	r.tail.Store(pos + 1)
+	return true
+}
+
+// enqueueMulti 有多个生产者的时候写入，生产者之间通过 CAS 抢占位置
+func (r *ring[T]) enqueueMulti(t T) bool {
+	pos := r.tail.Load()
+	for {
+		slot := &r.slots[pos&r.mask]
+		dif := int64(slot.seq.Load() - pos)
+		switch {
+		case dif == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				slot.val = t
+				slot.seq.Store(pos + 1)
+				return true
+			}
+			pos = r.tail.Load()
+		case dif < 0:
+			// 槽位还没有被消费者读走，说明满了
+			return false
+		default:
+			// 其它生产者已经抢先写入了
+			pos = r.tail.Load()
+		}
+	}
+}
+
+// dequeueSingle 只有一个消费者的时候读取
+func (r *ring[T]) dequeueSingle() (T, bool) {
+	pos := r.head.Load()
+	slot := &r.slots[pos&r.mask]
+	if slot.seq.Load() != pos+1 {
+		var t T
+		return t, false
+	}
+	t := slot.take()
+	slot.seq.Store(pos + r.mask + 1)
+	r.head.Store(pos + 1)
+	return t, true
+}
+
+// dequeueMulti 有多个消费者的时候读取，消费者之间通过 CAS 抢占位置
+func (r *ring[T]) dequeueMulti() (T, bool) {
+	pos := r.head.Load()
+	for {
+		slot := &r.slots[pos&r.mask]
+		dif := int64(slot.seq.Load() - (pos + 1))
+		switch {
+		case dif == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				t := slot.take()
+				slot.seq.Store(pos + r.mask + 1)
+				return t, true
+			}
+			pos = r.head.Load()
+		case dif < 0:
+			// 槽位还没有被生产者写入，说明空了
+			var t T
+			return t, false
+		default:
+			pos = r.head.Load()
+		}
+	}
+}
+
+// take 取出槽位中的元素，并且置为零值，避免内存泄露
+func (s *ringSlot[T]) take() T {
+	t := s.val
+	var zero T
+	s.val = zero
+	return t
+}
+
+// Len 返回元素的数量，在并发读写的时候这只是一个近似值
+func (r *ring[T]) Len() int {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if tail < head {
+		return 0
+	}
+	return int(tail - head)
+}
+
+// Cap 返回容量，是创建时设置的容量向上取整到 2 的幂
+func (r *ring[T]) Cap() int {
+	return len(r.slots)
+}
+
+// 下面是批量读写，和 Disruptor 一样，一次性占用连续的 n 个槽位：
+// 先从 pos 开始数出连续可用的槽位，然后只用一次 CAS（单生产者或者单消费者的时候直接写入）
+// 把 tail 或者 head 向后移动 n 个位置，最后填充这些槽位并且逐个发布序号。
+// 这里需要检查每一个槽位而不是只检查最后一个：有多个消费者或者生产者的时候它们完成的顺序是不确定的，
+// 最后一个槽位可用并不代表前面的槽位都可用。
+// 某个槽位的序号等于 p 之后，只有占用了位置 p 的 goroutine 才能修改它，
+// 所以 CAS 成功之后检查过的槽位依旧可用，不需要再等待。
+
+// readyCount 返回从 pos 开始连续可用的槽位数量，最多 n 个
+// 写入的时候 offset 为 0，读取的时候 offset 为 1，对应 ringSlot.seq 的两种状态
+func (r *ring[T]) readyCount(pos uint64, n int, offset uint64) int {
+	for i := 0; i < n; i++ {
+		p := pos + uint64(i)
+		if r.slots[p&r.mask].seq.Load() != p+offset {
+			return i
+		}
+	}
+	return n
+}
+
+// fill 把 ts 写入从 pos 开始的槽位，并且发布序号
+func (r *ring[T]) fill(pos uint64, ts []T) {
+	for i, t := range ts {
+		p := pos + uint64(i)
+		slot := &r.slots[p&r.mask]
+		slot.val = t
+		slot.seq.Store(p + 1)
+	}
+}
+
+// drain 从 pos 开始的槽位读取元素放入 dst，并且把槽位交还给生产者
+func (r *ring[T]) drain(pos uint64, dst []T) {
+	for i := range dst {
+		p := pos + uint64(i)
+		slot := &r.slots[p&r.mask]
+		dst[i] = slot.take()
+		slot.seq.Store(p + r.mask + 1)
+	}
+}
+
+// enqueueBatchSingle 只有一个生产者的时候批量写入
+func (r *ring[T]) enqueueBatchSingle(ts []T) int {
+	pos := r.tail.Load()
+	n := r.readyCount(pos, len(ts), 0)
+	r.fill(pos, ts[:n])
+	r.tail.Store(pos + uint64(n))
+	return n
+}
+
+// enqueueBatchMulti 有多个生产者的时候批量写入，一次 CAS 占用所有的槽位
+func (r *ring[T]) enqueueBatchMulti(ts []T) int {
+	if len(ts) == 0 {
+		return 0
+	}
+	for {
+		pos := r.tail.Load()
+		n := r.readyCount(pos, len(ts), 0)
+		if n == 0 {
+			if int64(r.slots[pos&r.mask].seq.Load()-pos) < 0 {
+				// 槽位还没有被消费者读走，说明满了
+				return 0
+			}
+			// 其它生产者已经抢先写入了
+			continue
+		}
+		if r.tail.CompareAndSwap(pos, pos+uint64(n)) {
+			r.fill(pos, ts[:n])
+			return n
+		}
+	}
+}
+
+// dequeueBatchSingle 只有一个消费者的时候批量读取
+func (r *ring[T]) dequeueBatchSingle(dst []T) int {
+	pos := r.head.Load()
+	n := r.readyCount(pos, len(dst), 1)
+	r.drain(pos, dst[:n])
+	r.head.Store(pos + uint64(n))
+	return n
+}
+
+// dequeueBatchMulti 有多个消费者的时候批量读取，一次 CAS 占用所有的槽位
+func (r *ring[T]) dequeueBatchMulti(dst []T) int {
+	if len(dst) == 0 {
+		return 0
+	}
+	for {
+		pos := r.head.Load()
+		n := r.readyCount(pos, len(dst), 1)
+		if n == 0 {
+			if int64(r.slots[pos&r.mask].seq.Load()-(pos+1)) < 0 {
+				// 槽位还没有被生产者写入，说明空了
+				return 0
+			}
+			continue
+		}
+		if r.head.CompareAndSwap(pos, pos+uint64(n)) {
+			r.drain(pos, dst[:n])
+			return n
+		}
+	}
+}
+
+// SPSCRingBuffer 是单生产者单消费者的有界环形缓冲区，遵循 FIFO
+// 同一时刻只能有一个 goroutine 写入，一个 goroutine 读取
+type SPSCRingBuffer[T any] struct {
+	*ring[T]
+}
+
+// NewSPSCRingBuffer 创建 SPSCRingBuffer，capacity 必须大于 0，并且会被向上取整到 2 的幂
+func NewSPSCRingBuffer[T any](capacity int) (*SPSCRingBuffer[T], error) {
+	r, err := newRing[T](capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &SPSCRingBuffer[T]{ring: r}, nil
+}
+
+// TryEnqueue 写入元素，满了的时候返回 false，不会阻塞
+func (s *SPSCRingBuffer[T]) TryEnqueue(t T) bool {
+	return s.enqueueSingle(t)
+}
+
+// TryDequeue 读取元素，空了的时候返回 false，不会阻塞
+func (s *SPSCRingBuffer[T]) TryDequeue() (T, bool) {
+	return s.dequeueSingle()
+}
+
+// TryEnqueueBatch 按顺序写入 ts 中的元素，直到满了为止，返回写入的数量
+func (s *SPSCRingBuffer[T]) TryEnqueueBatch(ts []T) int {
+	return s.enqueueBatchSingle(ts)
+}
+
+// TryDequeueBatch 读取元素放入 dst，直到空了或者 dst 满了为止，返回读取的数量
+func (s *SPSCRingBuffer[T]) TryDequeueBatch(dst []T) int {
+	return s.dequeueBatchSingle(dst)
+}
+
+// MPSCRingBuffer 是多生产者单消费者的有界环形缓冲区
+// 同一个生产者写入的元素保持 FIFO，同一时刻只能有一个 goroutine 读取
+type MPSCRingBuffer[T any] struct {
+	*ring[T]
+}
+
+// NewMPSCRingBuffer 创建 MPSCRingBuffer，capacity 必须大于 0，并且会被向上取整到 2 的幂
+func NewMPSCRingBuffer[T any](capacity int) (*MPSCRingBuffer[T], error) {
+	r, err := newRing[T](capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &MPSCRingBuffer[T]{ring: r}, nil
+}
+
+// TryEnqueue 写入元素，满了的时候返回 false，不会阻塞
+func (m *MPSCRingBuffer[T]) TryEnqueue(t T) bool {
+	return m.enqueueMulti(t)
+}
+
+// TryDequeue 读取元素，空了的时候返回 false，不会阻塞
+func (m *MPSCRingBuffer[T]) TryDequeue() (T, bool) {
+	return m.dequeueSingle()
+}
+
+// TryEnqueueBatch 按顺序写入 ts 中的元素，直到满了为止，返回写入的数量
+// 写入的元素在缓冲区中是连续的，不会和其它生产者的元素穿插
+func (m *MPSCRingBuffer[T]) TryEnqueueBatch(ts []T) int {
+	return m.enqueueBatchMulti(ts)
+}
+
+// TryDequeueBatch 读取元素放入 dst，直到空了或者 dst 满了为止，返回读取的数量
+func (m *MPSCRingBuffer[T]) TryDequeueBatch(dst []T) int {
+	return m.dequeueBatchSingle(dst)
+}
+
+// MPMCRingBuffer 是多生产者多消费者的有界环形缓冲区
+type MPMCRingBuffer[T any] struct {
+	*ring[T]
+}
+
+// NewMPMCRingBuffer 创建 MPMCRingBuffer，capacity 必须大于 0，并且会被向上取整到 2 的幂
+func NewMPMCRingBuffer[T any](capacity int) (*MPMCRingBuffer[T], error) {
+	r, err := newRing[T](capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &MPMCRingBuffer[T]{ring: r}, nil
+}
+
+// TryEnqueue 写入元素，满了的时候返回 false，不会阻塞
+func (m *MPMCRingBuffer[T]) TryEnqueue(t T) bool {
+	return m.enqueueMulti(t)
+}
+
+// TryDequeue 读取元素，空了的时候返回 false，不会阻塞
+func (m *MPMCRingBuffer[T]) TryDequeue() (T, bool) {
+	return m.dequeueMulti()
+}
+
+// TryEnqueueBatch 按顺序写入 ts 中的元素，直到满了为止，返回写入的数量
+// 写入的元素在缓冲区中是连续的，不会和其它生产者的元素穿插
+func (m *MPMCRingBuffer[T]) TryEnqueueBatch(ts []T) int {
+	return m.enqueueBatchMulti(ts)
+}
+
+// TryDequeueBatch 读取元素放入 dst，直到空了或者 dst 满了为止，返回读取的数量
+// 读到的元素在缓冲区中是连续的，中间不会有元素被其它消费者取走
+func (m *MPMCRingBuffer[T]) TryDequeueBatch(dst []T) int {
+	return m.dequeueBatchMulti(dst)
+}
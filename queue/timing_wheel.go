@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+var errInvalidTimingWheel = errors.New("xkit: 时间轮的刻度必须大于 0，并且每一层的槽位数量至少为 2")
+
+// TimingWheel 是分层时间轮，用于管理大量的定时任务
+// 第 k 层每个槽位的跨度是 tick * wheelSize^k，到期时间较远的任务放在高层，
+// 随着时间推进逐层下沉到低层，最终在第 0 层到期执行。层数会按需增加
+// 添加和取消任务都是 O(1) 的，推进时间由调用者驱动，因此测试的时候可以精确控制
+type TimingWheel struct {
+	mutex     sync.Mutex
+	tick      time.Duration
+	wheelSize uint64
+	// wheels[k][slot] 是第 k 层某个槽位上的任务
+	wheels [][]*twBucket
+	// spans[k] 是第 k 层一个槽位跨越的刻度数量
+	spans []uint64
+	// current 是已经推进的刻度数量
+	current uint64
+	length  int
+}
+
+// NewTimingWheel 创建时间轮，tick 是最小的时间精度，wheelSize 是每一层的槽位数量
+func NewTimingWheel(tick time.Duration, wheelSize int) (*TimingWheel, error) {
+	if tick <= 0 || wheelSize < 2 {
+		return nil, errInvalidTimingWheel
+	}
+	tw := &TimingWheel{
+		tick:      tick,
+		wheelSize: uint64(wheelSize),
+	}
+	tw.addLevel()
+	return tw, nil
+}
+
+// Schedule 在 d 之后执行 fn，d 会被向上取整到 tick 的整数倍，并且至少为一个 tick
+// fn 会在调用 Tick 的 goroutine 中执行，所以它不应该阻塞太久
+func (tw *TimingWheel) Schedule(d time.Duration, fn func()) *TimingWheelTimer {
+	ticks := uint64(1)
+	if d > tw.tick {
+		ticks = uint64((d + tw.tick - 1) / tw.tick)
+	}
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	t := &TimingWheelTimer{
+		tw:     tw,
+		expire: tw.current + ticks,
+		fn:     fn,
+	}
+	tw.add(t)
+	tw.length++
+	return t
+}
+
+// Tick 推进一个刻度，并且执行所有到期的任务
+func (tw *TimingWheel) Tick() {
+	tw.mutex.Lock()
+	tw.current++
+	var expired []*TimingWheelTimer
+	// 先把高层到期的槽位下沉，再处理第 0 层
+	for k := len(tw.wheels) - 1; k > 0; k-- {
+		if tw.current%tw.spans[k] != 0 {
+			continue
+		}
+		b := tw.wheels[k][(tw.current/tw.spans[k])%tw.wheelSize]
+		for _, t := range b.takeAll() {
+			if t.expire <= tw.current {
+				expired = append(expired, t)
+				continue
+			}
+			tw.add(t)
+		}
+	}
+	expired = append(expired, tw.wheels[0][tw.current%tw.wheelSize].takeAll()...)
+	tw.length -= len(expired)
+	tw.mutex.Unlock()
+	for _, t := range expired {
+		t.fn()
+	}
+}
+
+// Run 每次从 ticker 收到信号的时候推进一个刻度，直到 ctx 被取消
+// 一般传入 time.NewTicker(tick).C，测试的时候可以传入手动控制的 channel
+func (tw *TimingWheel) Run(ctx context.Context, ticker <-chan time.Time) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker:
+			tw.Tick()
+		}
+	}
+}
+
+// Len 返回还没有到期也没有被取消的任务数量
+func (tw *TimingWheel) Len() int {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+	return tw.length
+}
+
+// add 把任务放到对应的层和槽位上，调用者需要持有锁
+func (tw *TimingWheel) add(t *TimingWheelTimer) {
+	diff := t.expire - tw.current
+	k := 0
+	// 最高的一层如果继续相乘会溢出，那么它已经可以容纳任何任务
+	for ; tw.spans[k] <= math.MaxUint64/tw.wheelSize && diff >= tw.spans[k]*tw.wheelSize; k++ {
+		if k == len(tw.wheels)-1 {
+			tw.addLevel()
+		}
+	}
+	tw.wheels[k][(t.expire/tw.spans[k])%tw.wheelSize].push(t)
+}
+
+func (tw *TimingWheel) addLevel() {
+	span := uint64(1)
+	if n := len(tw.spans); n > 0 {
+		span = tw.spans[n-1] * tw.wheelSize
+	}
+	wheel := make([]*twBucket, tw.wheelSize)
+	for i := range wheel {
+		wheel[i] = newTWBucket()
+	}
+	tw.wheels = append(tw.wheels, wheel)
+	tw.spans = append(tw.spans, span)
+}
+
+// TimingWheelTimer 是 TimingWheel 上的一个任务
+type TimingWheelTimer struct {
+	tw     *TimingWheel
+	expire uint64
+	fn     func()
+	// bucket 是任务所在的槽位，任务到期或者被取消之后为 nil
+	bucket     *twBucket
+	prev, next *TimingWheelTimer
+}
+
+// Stop 取消任务，如果任务已经执行或者已经被取消，那么返回 false
+func (t *TimingWheelTimer) Stop() bool {
+	t.tw.mutex.Lock()
+	defer t.tw.mutex.Unlock()
+	if t.bucket == nil {
+		return false
+	}
+	t.bucket.remove(t)
+	t.tw.length--
+	return true
+}
+
+// twBucket 是一个槽位，用双向链表存放任务，以便 O(1) 取消
+type twBucket struct {
+	// root 是哨兵节点
+	root TimingWheelTimer
+}
+
+func newTWBucket() *twBucket {
+	b := &twBucket{}
+	b.root.prev, b.root.next = &b.root, &b.root
+	return b
+}
+
+func (b *twBucket) push(t *TimingWheelTimer) {
+	t.bucket = b
+	t.prev, t.next = b.root.prev, &b.root
+	b.root.prev.next = t
+	b.root.prev = t
+}
+
+func (b *twBucket) remove(t *TimingWheelTimer) {
+	t.prev.next, t.next.prev = t.next, t.prev
+	t.prev, t.next, t.bucket = nil, nil, nil
+}
+
+// takeAll 清空槽位，返回其中所有的任务
+func (b *twBucket) takeAll() []*TimingWheelTimer {
+	var res []*TimingWheelTimer
+	for t := b.root.next; t != &b.root; {
+		next := t.next
+		t.prev, t.next, t.bucket = nil, nil, nil
+		res = append(res, t)
+		t = next
+	}
+	b.root.prev, b.root.next = &b.root, &b.root
+	return res
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimingWheel(t *testing.T) {
+	_, err := NewTimingWheel(0, 8)
+	assert.Equal(t, errInvalidTimingWheel, err)
+	_, err = NewTimingWheel(time.Millisecond, 1)
+	assert.Equal(t, errInvalidTimingWheel, err)
+}
+
+func TestTimingWheel_Schedule(t *testing.T) {
+	testCases := []struct {
+		name      string
+		d         time.Duration
+		wantTicks int
+	}{
+		{name: "小于一个刻度", d: 0, wantTicks: 1},
+		{name: "一个刻度", d: time.Millisecond, wantTicks: 1},
+		{name: "向上取整", d: 1500 * time.Microsecond, wantTicks: 2},
+		{name: "第 0 层最后一个槽位", d: 3 * time.Millisecond, wantTicks: 3},
+		{name: "第 1 层", d: 4 * time.Millisecond, wantTicks: 4},
+		{name: "第 1 层中间", d: 11 * time.Millisecond, wantTicks: 11},
+		{name: "第 2 层", d: 37 * time.Millisecond, wantTicks: 37},
+		{name: "自动增加层数", d: 1000 * time.Millisecond, wantTicks: 1000},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tw, err := NewTimingWheel(time.Millisecond, 4)
+			require.NoError(t, err)
+			// 先推进几个刻度，让当前时间不在槽位的边界上
+			for i := 0; i < 3; i++ {
+				tw.Tick()
+			}
+			fired := 0
+			tw.Schedule(tc.d, func() { fired++ })
+			assert.Equal(t, 1, tw.Len())
+			for i := 1; i < tc.wantTicks; i++ {
+				tw.Tick()
+				require.Equal(t, 0, fired, "第 %d 个刻度提前执行了", i)
+			}
+			tw.Tick()
+			assert.Equal(t, 1, fired)
+			assert.Equal(t, 0, tw.Len())
+		})
+	}
+}
+
+func TestTimingWheel_Stop(t *testing.T) {
+	tw, err := NewTimingWheel(time.Second, 8)
+	require.NoError(t, err)
+	var fired []int
+	timers := make([]*TimingWheelTimer, 0, 3)
+	for i := 0; i < 3; i++ {
+		val := i
+		timers = append(timers, tw.Schedule(time.Duration(i+1)*time.Minute, func() {
+			fired = append(fired, val)
+		}))
+	}
+	assert.True(t, timers[1].Stop())
+	assert.False(t, timers[1].Stop())
+	assert.Equal(t, 2, tw.Len())
+	for i := 0; i < 180; i++ {
+		tw.Tick()
+	}
+	assert.Equal(t, []int{0, 2}, fired)
+	// 已经执行过的任务不能取消
+	assert.False(t, timers[0].Stop())
+	assert.Equal(t, 0, tw.Len())
+}
+
+// 随机添加和取消任务，每个任务都在它的到期刻度上执行
+func TestTimingWheel_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tw, err := NewTimingWheel(time.Millisecond, 8)
+	require.NoError(t, err)
+	const n = 5000
+	now := 0
+	want := make(map[int]int, n)
+	got := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		id := i
+		ticks := r.Intn(3000) + 1
+		timer := tw.Schedule(time.Duration(ticks)*time.Millisecond, func() {
+			got[id] = now
+		})
+		if r.Intn(5) == 0 {
+			timer.Stop()
+		} else {
+			want[id] = now + ticks
+		}
+		if r.Intn(2) == 0 {
+			now++
+			tw.Tick()
+		}
+	}
+	for tw.Len() > 0 {
+		now++
+		tw.Tick()
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestTimingWheel_Run(t *testing.T) {
+	tw, err := NewTimingWheel(time.Millisecond, 8)
+	require.NoError(t, err)
+	ticker := make(chan time.Time)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, context.Canceled, tw.Run(ctx, ticker))
+	}()
+	fired := make(chan struct{})
+	tw.Schedule(2*time.Millisecond, func() { close(fired) })
+	ticker <- time.Now()
+	ticker <- time.Now()
+	<-fired
+	cancel()
+	wg.Wait()
+}
+
+func BenchmarkTimingWheel_Schedule(b *testing.B) {
+	tw, err := NewTimingWheel(time.Millisecond, 512)
+	require.NoError(b, err)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tw.Schedule(time.Duration(i%100000)*time.Millisecond, func() {}).Stop()
+	}
+}
@@ -1,5 +1,7 @@
 package queue
 
+import "context"
+
 // Queue 普通队列
 // 参考 BlockingQueue 阻塞队列
 // 一个队列是否遵循 FIFO 取决于具体实现
@@ -10,3 +12,15 @@ type Queue[T any] interface {
 	// 如果一个队列里面没有元素，那么返回错误
 	Dequeue() (T, error)
 }
+
+// BlockingQueue 阻塞队列
+// 参考 Queue 普通队列
+// 一个阻塞队列是否遵循 FIFO 取决于具体实现
+type BlockingQueue[T any] interface {
+	// Enqueue 将元素放入队列。如果此队列已经满了，那么会阻塞直到有空闲位置，
+	// 或者 ctx 被取消，此时返回 ctx.Err()
+	Enqueue(ctx context.Context, t T) error
+	// Dequeue 从队首获得一个元素。如果此队列里面没有元素，那么会阻塞直到有元素，
+	// 或者 ctx 被取消，此时返回 ctx.Err()
+	Dequeue(ctx context.Context) (T, error)
+}
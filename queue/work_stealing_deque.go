@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"sync/atomic"
+)
+
+// wsBuffer 是 WorkStealingDeque 的环形数组
+// 槽位使用原子指针，因为窃取者读取槽位的时候，所有者可能正在写入同一个槽位
+type wsBuffer[T any] struct {
+	slots []atomic.Pointer[T]
+	mask  int64
+}
+
+func newWSBuffer[T any](size int64) *wsBuffer[T] {
+	return &wsBuffer[T]{
+		slots: make([]atomic.Pointer[T], size),
+		mask:  size - 1,
+	}
+}
+
+func (b *wsBuffer[T]) size() int64 {
+	return b.mask + 1
+}
+
+func (b *wsBuffer[T]) get(i int64) *T {
+	return b.slots[i&b.mask].Load()
+}
+
+func (b *wsBuffer[T]) put(i int64, t *T) {
+	b.slots[i&b.mask].Store(t)
+}
+
+// grow 返回两倍大小的数组，并且复制 [top, bottom) 中的元素
+func (b *wsBuffer[T]) grow(top, bottom int64) *wsBuffer[T] {
+	nb := newWSBuffer[T](b.size() * 2)
+	for i := top; i < bottom; i++ {
+		nb.put(i, b.get(i))
+	}
+	return nb
+}
+
+// WorkStealingDeque 是无锁的工作窃取双端队列，采用了 Chase–Lev 算法
+// 只有一个所有者可以调用 Push 和 Pop，在底部放入和取出元素，也就是 LIFO；
+// 任意数量的窃取者可以并发调用 Steal，从顶部取走元素，也就是 FIFO
+// 数组满了的时候会自动扩容，旧的数组交给 GC 回收
+// 被 Pop 取走的元素会立刻从数组中清除；被窃取的元素在所有者下一次 Push 的时候清除，在此之前不会被 GC 回收
+type WorkStealingDeque[T any] struct {
+	top    atomic.Int64
+	_      cacheLinePad
+	bottom atomic.Int64
+	buffer atomic.Pointer[wsBuffer[T]]
+	// cleared 之前被窃取的元素的槽位都已经清空了，只有所有者会读写
+	cleared int64
+}
+
+// NewWorkStealingDeque 创建 WorkStealingDeque，capacity 是初始容量，会被向上取整到 2 的幂
+func NewWorkStealingDeque[T any](capacity int) *WorkStealingDeque[T] {
+	size := int64(8)
+	for size < int64(capacity) {
+		size <<= 1
+	}
+	d := &WorkStealingDeque[T]{}
+	d.buffer.Store(newWSBuffer[T](size))
+	return d
+}
+
+// Push 在底部放入元素，只有所有者可以调用
+func (d *WorkStealingDeque[T]) Push(t T) {
+	b := d.bottom.Load()
+	top := d.top.Load()
+	buf := d.buffer.Load()
+	if b-top >= buf.size() {
+		buf = buf.grow(top, b)
+		d.buffer.Store(buf)
+	}
+	d.clearStolen(buf, top, b)
+	buf.put(b, &t)
+	d.bottom.Store(b + 1)
+}
+
+// Pop 从底部取出元素，只有所有者可以调用
+func (d *WorkStealingDeque[T]) Pop() (T, bool) {
+	var zero T
+	b := d.bottom.Load() - 1
+	buf := d.buffer.Load()
+	// 先占住底部的元素，窃取者看到之后就不会再尝试窃取它
+	d.bottom.Store(b)
+	top := d.top.Load()
+	if top > b {
+		// 队列是空的
+		d.bottom.Store(b + 1)
+		return zero, false
+	}
+	t := buf.get(b)
+	if top < b {
+		// 窃取者只会读取 top 位置的槽位，所以可以直接清空
+		buf.put(b, nil)
+		return *t, true
+	}
+	// 只剩最后一个元素，需要和窃取者竞争
+	ok := d.top.CompareAndSwap(top, top+1)
+	d.bottom.Store(b + 1)
+	if !ok {
+		return zero, false
+	}
+	buf.put(b, nil)
+	return *t, true
+}
+
+// Steal 从顶部窃取元素，可以被任意 goroutine 并发调用
+// 返回 false 表示队列是空的，或者和其它窃取者以及所有者竞争失败了
+func (d *WorkStealingDeque[T]) Steal() (T, bool) {
+	var zero T
+	top := d.top.Load()
+	b := d.bottom.Load()
+	if top >= b {
+		return zero, false
+	}
+	t := d.buffer.Load().get(top)
+	if !d.top.CompareAndSwap(top, top+1) {
+		return zero, false
+	}
+	return *t, true
+}
+
+// clearStolen 清空 [cleared, top) 中已经被窃取的槽位，避免窃取走的元素一直不能被 GC 回收
+// 窃取者取走元素之后，所有者可能马上就复用了这个槽位，所以窃取者自己不能清空，只能由所有者清空
+// 下标小于 bottom - size 的槽位已经被更新的元素覆盖了，不需要清空
+func (d *WorkStealingDeque[T]) clearStolen(buf *wsBuffer[T], top, bottom int64) {
+	for i := max(d.cleared, bottom-buf.size()); i < top; i++ {
+		buf.put(i, nil)
+	}
+	d.cleared = top
+}
+
+// Len 返回元素的数量，在并发读写的时候这只是一个近似值
+func (d *WorkStealingDeque[T]) Len() int {
+	n := d.bottom.Load() - d.top.Load()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkStealingDeque(t *testing.T) {
+	d := NewWorkStealingDeque[int](0)
+	_, ok := d.Pop()
+	assert.False(t, ok)
+	_, ok = d.Steal()
+	assert.False(t, ok)
+
+	// 超过初始容量，会扩容
+	for i := 0; i < 20; i++ {
+		d.Push(i)
+	}
+	assert.Equal(t, 20, d.Len())
+	assert.Equal(t, int64(32), d.buffer.Load().size())
+
+	// 窃取者从顶部取，所有者从底部取
+	for i := 0; i < 5; i++ {
+		val, ok := d.Steal()
+		assert.True(t, ok)
+		assert.Equal(t, i, val)
+	}
+	for i := 19; i >= 5; i-- {
+		val, ok := d.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, val)
+	}
+	_, ok = d.Pop()
+	assert.False(t, ok)
+	assert.Equal(t, 0, d.Len())
+
+	// 扩容之后环形数组绕回的情况
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 30; i++ {
+			d.Push(i)
+		}
+		for i := 0; i < 30; i++ {
+			val, ok := d.Steal()
+			assert.True(t, ok)
+			assert.Equal(t, i, val)
+		}
+	}
+	assert.Equal(t, int64(32), d.buffer.Load().size())
+}
+
+func TestWorkStealingDeque_ClearSlots(t *testing.T) {
+	d := NewWorkStealingDeque[*int](8)
+	// used 返回还引用着元素的槽位数量
+	used := func() int {
+		cnt := 0
+		buf := d.buffer.Load()
+		for i := range buf.slots {
+			if buf.slots[i].Load() != nil {
+				cnt++
+			}
+		}
+		return cnt
+	}
+	for i := 0; i < 6; i++ {
+		d.Push(new(int))
+	}
+	_, ok := d.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 5, used())
+	for i := 0; i < 2; i++ {
+		_, ok = d.Steal()
+		assert.True(t, ok)
+	}
+	// 被窃取的槽位要等到下一次 Push 才清空
+	assert.Equal(t, 5, used())
+	d.Push(new(int))
+	assert.Equal(t, 4, used())
+
+	// 取走所有的元素，包括和窃取者竞争的最后一个元素
+	for d.Len() > 1 {
+		_, ok = d.Steal()
+		assert.True(t, ok)
+	}
+	_, ok = d.Pop()
+	assert.True(t, ok)
+	d.Push(new(int))
+	assert.Equal(t, 1, used())
+
+	// 扩容之后旧数组中被窃取的元素不会被复制，新数组中的也会被清空
+	for i := 0; i < 20; i++ {
+		d.Push(new(int))
+		_, ok = d.Steal()
+		assert.True(t, ok)
+		d.Push(new(int))
+	}
+	assert.Equal(t, int64(32), d.buffer.Load().size())
+	assert.Equal(t, d.Len(), used())
+}
+
+// 所有者不断放入和取出，多个窃取者并发窃取，每个元素都恰好被取走一次
+func TestWorkStealingDeque_Concurrent(t *testing.T) {
+	const n, thieves = 20000, 4
+	d := NewWorkStealingDeque[int](0)
+	taken := make([]atomic.Int32, n)
+	var remaining atomic.Int64
+	remaining.Store(n)
+	take := func(val int) {
+		taken[val].Add(1)
+		remaining.Add(-1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remaining.Load() > 0 {
+				if val, ok := d.Steal(); ok {
+					take(val)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		d.Push(i)
+		// 每放入三个元素，所有者自己取出一个
+		if i%3 == 2 {
+			if val, ok := d.Pop(); ok {
+				take(val)
+			}
+		}
+	}
+	for remaining.Load() > 0 {
+		if val, ok := d.Pop(); ok {
+			take(val)
+		} else {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+	for i := range taken {
+		assert.Equal(t, int32(1), taken[i].Load(), "元素 %d", i)
+	}
+	assert.Equal(t, 0, d.Len())
+}
@@ -0,0 +1,317 @@
+package set
+
+import (
+	"reflect"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/WeiXinao/xkit/mapx"
+)
+
+// 以下是集合之间的运算，运算的结果都是新的集合，不会修改参与运算的集合
+// 元素不要求是 comparable 的，所以也可以用于元素不可比较的 TreeSet
+// 如果两个集合都是 TreeSet，那么会按顺序归并两个集合，时间复杂度是 O(n+m)，结果也是 TreeSet，
+// 此时两个 TreeSet 应该使用相同的比较器；否则通过 Exist 判断元素是否存在，并且尽量只遍历较小的集合，
+// 结果和 a 是同一种集合，例如 a 是 TreeSet 的时候结果是使用相同比较器的 TreeSet
+// 两个集合都不能提供结果的类型时（例如其它包中实现的集合），结果是按照线性查找实现的 listSet，
+// 这时候元素是否重复完全由参与运算的集合的 Exist 判断，不会对元素做哈希
+
+// Union 并集
+func Union[T any](a, b Set[T]) Set[T] {
+	if ta, tb, ok := treeSets(a, b); ok {
+		return ta.merge(tb, func(inA, inB bool) bool {
+			return true
+		})
+	}
+	res := newResult(a, b)
+	a.Range(func(key T) bool {
+		addDistinct(res, key)
+		return true
+	})
+	b.Range(func(key T) bool {
+		if !a.Exist(key) {
+			addDistinct(res, key)
+		}
+		return true
+	})
+	return res
+}
+
+// Intersect 交集
+func Intersect[T any](a, b Set[T]) Set[T] {
+	if ta, tb, ok := treeSets(a, b); ok {
+		return ta.merge(tb, func(inA, inB bool) bool {
+			return inA && inB
+		})
+	}
+	small, large := a, b
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	res := newResult(a, b)
+	small.Range(func(key T) bool {
+		if large.Exist(key) {
+			addDistinct(res, key)
+		}
+		return true
+	})
+	return res
+}
+
+// Difference 差集，也就是在 a 中但是不在 b 中的元素
+func Difference[T any](a, b Set[T]) Set[T] {
+	if ta, tb, ok := treeSets(a, b); ok {
+		return ta.merge(tb, func(inA, inB bool) bool {
+			return inA && !inB
+		})
+	}
+	res := newResult(a, b)
+	a.Range(func(key T) bool {
+		if !b.Exist(key) {
+			addDistinct(res, key)
+		}
+		return true
+	})
+	return res
+}
+
+// SymmetricDifference 对称差集，也就是只在其中一个集合中的元素
+func SymmetricDifference[T any](a, b Set[T]) Set[T] {
+	if ta, tb, ok := treeSets(a, b); ok {
+		return ta.merge(tb, func(inA, inB bool) bool {
+			return inA != inB
+		})
+	}
+	res := newResult(a, b)
+	a.Range(func(key T) bool {
+		if !b.Exist(key) {
+			addDistinct(res, key)
+		}
+		return true
+	})
+	b.Range(func(key T) bool {
+		if !a.Exist(key) {
+			addDistinct(res, key)
+		}
+		return true
+	})
+	return res
+}
+
+// IsSubset 判断 a 是否是 b 的子集
+func IsSubset[T any](a, b Set[T]) bool {
+	if a.Len() > b.Len() {
+		return false
+	}
+	res := true
+	if ta, tb, ok := treeSets(a, b); ok {
+		mergeSorted(ta.treeMap.Keys(), tb.treeMap.Keys(), ta.compare, func(key T, inA, inB bool) bool {
+			res = !inA || inB
+			return res
+		})
+		return res
+	}
+	a.Range(func(key T) bool {
+		res = b.Exist(key)
+		return res
+	})
+	return res
+}
+
+// IsSuperset 判断 a 是否是 b 的超集
+func IsSuperset[T any](a, b Set[T]) bool {
+	return IsSubset(b, a)
+}
+
+// IsDisjoint 判断两个集合是否没有公共的元素
+func IsDisjoint[T any](a, b Set[T]) bool {
+	res := true
+	if ta, tb, ok := treeSets(a, b); ok {
+		mergeSorted(ta.treeMap.Keys(), tb.treeMap.Keys(), ta.compare, func(key T, inA, inB bool) bool {
+			res = !(inA && inB)
+			return res
+		})
+		return res
+	}
+	small, large := a, b
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	small.Range(func(key T) bool {
+		res = !large.Exist(key)
+		return res
+	})
+	return res
+}
+
+// emptier 由集合自己提供一个同类型的空集合，集合运算用它来创建结果
+type emptier[T any] interface {
+	newEmpty() Set[T]
+}
+
+// newResult 创建和 a 同类型的空集合，a 不能提供的时候和 b 同类型，
+// 两个都不能提供的时候使用 listSet
+func newResult[T any](a, b Set[T]) Set[T] {
+	if e, ok := a.(emptier[T]); ok {
+		return e.newEmpty()
+	}
+	if e, ok := b.(emptier[T]); ok {
+		return e.newEmpty()
+	}
+	return newListSet[T]()
+}
+
+// addDistinct 将 key 加入到运算的结果中，调用者已经通过参与运算的集合确认过 key 不在 res 中
+// listSet 的 Add 需要线性查找，所以这里直接追加
+func addDistinct[T any](res Set[T], key T) {
+	if l, ok := res.(*listSet[T]); ok {
+		l.keys = append(l.keys, key)
+		return
+	}
+	res.Add(key)
+}
+
+func treeSets[T any](a, b Set[T]) (*TreeSet[T], *TreeSet[T], bool) {
+	ta, ok := a.(*TreeSet[T])
+	if !ok {
+		return nil, nil, false
+	}
+	tb, ok := b.(*TreeSet[T])
+	return ta, tb, ok
+}
+
+// merge 归并两个 TreeSet，keep 决定结果中是否保留某个元素
+// 归并得到的元素已经有序，所以直接用它们构造红黑树，整体的时间复杂度是 O(n+m)
+func (s *TreeSet[T]) merge(other *TreeSet[T], keep func(inA, inB bool) bool) *TreeSet[T] {
+	keys := make([]T, 0)
+	mergeSorted(s.treeMap.Keys(), other.treeMap.Keys(), s.compare, func(key T, inA, inB bool) bool {
+		if keep(inA, inB) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	// compare 不为 nil，keys 严格递增，所以不会返回错误
+	treeMap, _ := mapx.NewTreeMapFromSorted[T, any](s.compare, keys, nil)
+	return &TreeSet[T]{
+		compare: s.compare,
+		treeMap: treeMap,
+	}
+}
+
+// mergeSorted 按顺序归并两个有序的切片，对于每一个元素，告诉 fn 它是否在 a 中以及是否在 b 中
+// fn 返回 false 的时候中断归并
+func mergeSorted[T any](a, b []T, compare xkit.Comparator[T], fn func(key T, inA, inB bool) bool) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		var ok bool
+		switch c := compare(a[i], b[j]); {
+		case c < 0:
+			ok = fn(a[i], true, false)
+			i++
+		case c > 0:
+			ok = fn(b[j], false, true)
+			j++
+		default:
+			ok = fn(a[i], true, true)
+			i++
+			j++
+		}
+		if !ok {
+			return
+		}
+	}
+	for ; i < len(a); i++ {
+		if !fn(a[i], true, false) {
+			return
+		}
+	}
+	for ; j < len(b); j++ {
+		if !fn(b[j], false, true) {
+			return
+		}
+	}
+}
+
+// listSet 是参与运算的集合都不能提供结果类型时使用的集合
+// 元素保存在切片中，所有的操作都是线性查找，不会对元素做哈希，所以元素可以是切片这种不可比较的类型
+// 元素的动态值可以比较的时候使用 ==，否则使用 reflect.DeepEqual
+type listSet[T any] struct {
+	keys []T
+}
+
+func newListSet[T any]() *listSet[T] {
+	return &listSet[T]{}
+}
+
+func (s *listSet[T]) Add(key T) {
+	if !s.Exist(key) {
+		s.keys = append(s.keys, key)
+	}
+}
+
+func (s *listSet[T]) Delete(key T) {
+	if i := s.index(key); i >= 0 {
+		last := len(s.keys) - 1
+		s.keys[i] = s.keys[last]
+		var zero T
+		s.keys[last] = zero
+		s.keys = s.keys[:last]
+	}
+}
+
+func (s *listSet[T]) Exist(key T) bool {
+	return s.index(key) >= 0
+}
+
+func (s *listSet[T]) Keys() []T {
+	res := make([]T, len(s.keys))
+	copy(res, s.keys)
+	return res
+}
+
+func (s *listSet[T]) Len() int {
+	return len(s.keys)
+}
+
+func (s *listSet[T]) Range(fn func(key T) bool) {
+	for _, key := range s.keys {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (s *listSet[T]) Clear() {
+	s.keys = nil
+}
+
+func (s *listSet[T]) AddAll(keys ...T) {
+	for _, key := range keys {
+		s.Add(key)
+	}
+}
+
+func (s *listSet[T]) Equal(other Set[T]) bool {
+	return equalSet[T](s, other)
+}
+
+func (s *listSet[T]) newEmpty() Set[T] {
+	return newListSet[T]()
+}
+
+func (s *listSet[T]) index(key T) int {
+	for i, k := range s.keys {
+		if equalKey(k, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// equalKey 判断两个元素是否相等，动态值不可比较的时候使用 reflect.DeepEqual，避免 == 在运行时 panic
+func equalKey[T any](src, dst T) bool {
+	sv, dv := reflect.ValueOf(&src).Elem(), reflect.ValueOf(&dst).Elem()
+	if sv.Comparable() && dv.Comparable() {
+		return sv.Equal(dv)
+	}
+	return reflect.DeepEqual(src, dst)
+}
@@ -0,0 +1,391 @@
+package set
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSetPairs 返回不同实现组合的两个集合
+func testSetPairs(t *testing.T, a, b []int) map[string][2]Set[int] {
+	newMapSet := func(keys []int) Set[int] {
+		s := NewMapSet[int](len(keys))
+		s.AddAll(keys...)
+		return s
+	}
+	newTreeSet := func(keys []int) Set[int] {
+		s, err := NewTreeSet[int](compare())
+		require.NoError(t, err)
+		s.AddAll(keys...)
+		return s
+	}
+	return map[string][2]Set[int]{
+		"map-map":   {newMapSet(a), newMapSet(b)},
+		"tree-tree": {newTreeSet(a), newTreeSet(b)},
+		"map-tree":  {newMapSet(a), newTreeSet(b)},
+		"tree-map":  {newTreeSet(a), newMapSet(b)},
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	testCases := []struct {
+		name        string
+		a, b        []int
+		union       []int
+		intersect   []int
+		difference  []int
+		symmetric   []int
+		isSubset    bool
+		isSuperset  bool
+		isDisjoint  bool
+		wantIsEqual bool
+	}{
+		{
+			name:       "部分重叠",
+			a:          []int{1, 2, 3, 4},
+			b:          []int{3, 4, 5},
+			union:      []int{1, 2, 3, 4, 5},
+			intersect:  []int{3, 4},
+			difference: []int{1, 2},
+			symmetric:  []int{1, 2, 5},
+		},
+		{
+			name:       "子集",
+			a:          []int{2, 3},
+			b:          []int{1, 2, 3},
+			union:      []int{1, 2, 3},
+			intersect:  []int{2, 3},
+			difference: []int{},
+			symmetric:  []int{1},
+			isSubset:   true,
+		},
+		{
+			name:       "超集",
+			a:          []int{1, 2, 3},
+			b:          []int{3},
+			union:      []int{1, 2, 3},
+			intersect:  []int{3},
+			difference: []int{1, 2},
+			symmetric:  []int{1, 2},
+			isSuperset: true,
+		},
+		{
+			name:       "不相交",
+			a:          []int{1, 3},
+			b:          []int{2, 4},
+			union:      []int{1, 2, 3, 4},
+			intersect:  []int{},
+			difference: []int{1, 3},
+			symmetric:  []int{1, 2, 3, 4},
+			isDisjoint: true,
+		},
+		{
+			name:        "相等",
+			a:           []int{1, 2},
+			b:           []int{2, 1},
+			union:       []int{1, 2},
+			intersect:   []int{1, 2},
+			difference:  []int{},
+			symmetric:   []int{},
+			isSubset:    true,
+			isSuperset:  true,
+			wantIsEqual: true,
+		},
+		{
+			name:       "空集",
+			a:          []int{},
+			b:          []int{1},
+			union:      []int{1},
+			intersect:  []int{},
+			difference: []int{},
+			symmetric:  []int{1},
+			isSubset:   true,
+			isDisjoint: true,
+		},
+	}
+	for _, tc := range testCases {
+		for name, pair := range testSetPairs(t, tc.a, tc.b) {
+			a, b := pair[0], pair[1]
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				assert.ElementsMatch(t, tc.union, Union(a, b).Keys())
+				assert.ElementsMatch(t, tc.intersect, Intersect(a, b).Keys())
+				assert.ElementsMatch(t, tc.difference, Difference(a, b).Keys())
+				assert.ElementsMatch(t, tc.symmetric, SymmetricDifference(a, b).Keys())
+				assert.Equal(t, tc.isSubset, IsSubset(a, b))
+				assert.Equal(t, tc.isSuperset, IsSuperset(a, b))
+				assert.Equal(t, tc.isDisjoint, IsDisjoint(a, b))
+				assert.Equal(t, tc.wantIsEqual, a.Equal(b))
+				// 参与运算的集合不受影响
+				assert.ElementsMatch(t, tc.a, a.Keys())
+				assert.ElementsMatch(t, tc.b, b.Keys())
+			})
+		}
+	}
+}
+
+func TestSetAlgebra_ResultType(t *testing.T) {
+	pairs := testSetPairs(t, []int{3, 1}, []int{2, 1})
+	trees := pairs["tree-tree"]
+	res := Union(trees[0], trees[1])
+	// 两个 TreeSet 运算的结果依旧是有序的 TreeSet
+	assert.IsType(t, &TreeSet[int]{}, res)
+	assert.Equal(t, []int{1, 2, 3}, res.Keys())
+	assert.IsType(t, &MapSet[int]{}, Union(pairs["map-tree"][0], pairs["map-tree"][1]))
+}
+
+func TestTreeSet_Merge(t *testing.T) {
+	a, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	b, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		a.Add(2 * i)
+		b.Add(3 * i)
+	}
+	res := Union(a, b).(*TreeSet[int])
+	// 归并直接构造出来的 TreeSet 可以继续正常地读写
+	res.Add(-1)
+	res.Delete(0)
+	assert.Equal(t, 1+1000+1000-334-1, res.Len())
+	assert.Equal(t, 1, res.Rank(2))
+	key, err := res.Select(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, key)
+	assert.True(t, res.Exist(2997))
+	assert.False(t, res.Exist(0))
+}
+
+// tagged 包含切片，所以不是 comparable 的，只能放在 TreeSet 中
+type tagged struct {
+	id   int
+	tags []string
+}
+
+func compareTagged(src, dst tagged) int {
+	return compare()(src.id, dst.id)
+}
+
+func TestSetAlgebra_NotComparable(t *testing.T) {
+	newTreeSet := func(ids ...int) *TreeSet[tagged] {
+		s, err := NewTreeSet[tagged](compareTagged)
+		require.NoError(t, err)
+		for _, id := range ids {
+			s.Add(tagged{id: id, tags: []string{"x"}})
+		}
+		return s
+	}
+	newConcurrentTreeSet := func(ids ...int) *ConcurrentTreeSet[tagged] {
+		s, err := NewConcurrentTreeSet[tagged](compareTagged)
+		require.NoError(t, err)
+		for _, id := range ids {
+			s.Add(tagged{id: id})
+		}
+		return s
+	}
+	ids := func(s Set[tagged]) []int {
+		res := make([]int, 0, s.Len())
+		s.Range(func(key tagged) bool {
+			res = append(res, key.id)
+			return true
+		})
+		return res
+	}
+
+	pairs := map[string][2]Set[tagged]{
+		"tree-tree":            {newTreeSet(1, 2, 3), newTreeSet(2, 3, 4)},
+		"tree-concurrent tree": {newTreeSet(1, 2, 3), newConcurrentTreeSet(2, 3, 4)},
+		"concurrent tree-tree": {newConcurrentTreeSet(1, 2, 3), newTreeSet(2, 3, 4)},
+	}
+	for name, pair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			a, b := pair[0], pair[1]
+			assert.Equal(t, []int{1, 2, 3, 4}, ids(Union(a, b)))
+			assert.Equal(t, []int{2, 3}, ids(Intersect(a, b)))
+			assert.Equal(t, []int{1}, ids(Difference(a, b)))
+			assert.Equal(t, []int{1, 4}, ids(SymmetricDifference(a, b)))
+			assert.False(t, IsSubset(a, b))
+			assert.False(t, IsSuperset(a, b))
+			assert.False(t, IsDisjoint(a, b))
+			assert.True(t, IsSubset(Intersect(a, b), a))
+			assert.True(t, IsDisjoint(Difference(a, b), b))
+		})
+	}
+}
+
+// opaqueSet 只暴露 Set 接口的方法，用于模拟其它包中实现的集合
+type opaqueSet[T any] struct {
+	Set[T]
+}
+
+func TestSetAlgebra_ResultFollowsA(t *testing.T) {
+	tree, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	tree.AddAll(1, 2)
+	m := NewMapSet[int](2)
+	m.AddAll(2, 3)
+	res := Union[int](tree, m)
+	// 结果和 a 是同一种集合，并且使用 a 的比较器
+	assert.IsType(t, &TreeSet[int]{}, res)
+	assert.Equal(t, []int{1, 2, 3}, res.Keys())
+
+	bits := NewBitSet(0)
+	bits.AddAll(1, 2)
+	other := NewMapSet[uint](2)
+	other.AddAll(2, 3)
+	assert.IsType(t, &BitSet{}, Intersect[uint](bits, other))
+	roaring := NewRoaring()
+	roaring.AddAll(1, 2)
+	assert.IsType(t, &Roaring{}, Difference[uint32](roaring, NewMapSet[uint32](0)))
+
+	// a 不能提供结果的类型时使用 b 的类型
+	assert.IsType(t, &MapSet[int]{}, Union[int](opaqueSet[int]{Set: tree}, m))
+
+	// 两个都不能提供的时候退化为 listSet
+	res = SymmetricDifference[int](opaqueSet[int]{Set: tree}, opaqueSet[int]{Set: m})
+	assert.IsType(t, &listSet[int]{}, res)
+	assert.ElementsMatch(t, []int{1, 3}, res.Keys())
+}
+
+// sliceSet 是其它包中实现的集合，元素是不可比较的切片，相等的判断由它自己决定
+type sliceSet struct {
+	keys [][]int
+}
+
+func newSliceSet(keys ...[]int) *sliceSet {
+	s := &sliceSet{}
+	s.AddAll(keys...)
+	return s
+}
+
+func (s *sliceSet) Add(key []int) {
+	if !s.Exist(key) {
+		s.keys = append(s.keys, key)
+	}
+}
+
+func (s *sliceSet) Delete(key []int) {
+	for i, k := range s.keys {
+		if slices.Equal(k, key) {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *sliceSet) Exist(key []int) bool {
+	for _, k := range s.keys {
+		if slices.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sliceSet) Keys() [][]int {
+	return slices.Clone(s.keys)
+}
+
+func (s *sliceSet) Len() int {
+	return len(s.keys)
+}
+
+func (s *sliceSet) Range(fn func(key []int) bool) {
+	for _, k := range s.keys {
+		if !fn(k) {
+			return
+		}
+	}
+}
+
+func (s *sliceSet) Clear() {
+	s.keys = nil
+}
+
+func (s *sliceSet) AddAll(keys ...[]int) {
+	for _, k := range keys {
+		s.Add(k)
+	}
+}
+
+func (s *sliceSet) Equal(other Set[[]int]) bool {
+	return equalSet[[]int](s, other)
+}
+
+func TestSetAlgebra_ThirdPartyNotComparable(t *testing.T) {
+	a := newSliceSet([]int{1}, []int{1, 2}, []int{3})
+	b := newSliceSet([]int{1, 2}, []int{3}, []int{4})
+	testCases := []struct {
+		name string
+		res  Set[[]int]
+		want [][]int
+	}{
+		{
+			name: "Union",
+			res:  Union[[]int](a, b),
+			want: [][]int{{1}, {1, 2}, {3}, {4}},
+		},
+		{
+			name: "Intersect",
+			res:  Intersect[[]int](a, b),
+			want: [][]int{{1, 2}, {3}},
+		},
+		{
+			name: "Difference",
+			res:  Difference[[]int](a, b),
+			want: [][]int{{1}},
+		},
+		{
+			name: "SymmetricDifference",
+			res:  SymmetricDifference[[]int](a, b),
+			want: [][]int{{1}, {4}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.IsType(t, &listSet[[]int]{}, tc.res)
+			assert.ElementsMatch(t, tc.want, tc.res.Keys())
+			for _, key := range tc.want {
+				assert.True(t, tc.res.Exist(key))
+			}
+			assert.True(t, tc.res.Equal(newSliceSet(tc.want...)))
+		})
+	}
+	assert.True(t, IsSubset[[]int](Intersect[[]int](a, b), a))
+	assert.True(t, IsDisjoint[[]int](Difference[[]int](a, b), b))
+	assert.False(t, IsDisjoint[[]int](a, b))
+}
+
+func TestListSet(t *testing.T) {
+	s := newListSet[int]()
+	s.AddAll(1, 2, 3, 2)
+	assert.Equal(t, 3, s.Len())
+	assert.True(t, s.Exist(2))
+	s.Delete(2)
+	assert.False(t, s.Exist(2))
+	assert.ElementsMatch(t, []int{1, 3}, s.Keys())
+
+	m := NewMapSet[int](2)
+	m.AddAll(1, 3)
+	assert.True(t, s.Equal(m))
+
+	cnt := 0
+	s.Range(func(key int) bool {
+		cnt++
+		return false
+	})
+	assert.Equal(t, 1, cnt)
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+
+	// 接口中的动态值不可比较的时候也不会 panic
+	vals := newListSet[any]()
+	vals.AddAll([]int{1}, []int{1}, 1, map[string]int{"a": 1})
+	assert.Equal(t, 3, vals.Len())
+	assert.True(t, vals.Exist([]int{1}))
+	assert.False(t, vals.Exist([]int{2}))
+	vals.Delete([]int{1})
+	assert.ElementsMatch(t, []any{1, map[string]int{"a": 1}}, vals.Keys())
+}
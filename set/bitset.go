@@ -0,0 +1,342 @@
+package set
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/bits"
+)
+
+var errBitSetInvalidData = errors.New("xkit: BitSet 二进制数据的长度必须是 8 的倍数")
+
+// BitSet 是非负整数的位图集合，第 i 位为 1 表示 i 在集合中
+// 内存占用只和最大的元素有关，每个元素只占一个比特，适合元素稠密分布的场景
+// 零值可以直接使用
+//
+// BitSet 实现了 Set[uint]，所以 Clear 用于清空整个集合，清除某一位使用 Delete
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet 创建 BitSet，length 是预计的最大元素加一，只用于预分配内存
+func NewBitSet(length uint) *BitSet {
+	return &BitSet{
+		words: make([]uint64, 0, wordsNeeded(length)),
+	}
+}
+
+func wordsNeeded(length uint) int {
+	return int((length + 63) >> 6)
+}
+
+// grow 保证至少有 n 个字
+func (b *BitSet) grow(n int) {
+	if n > len(b.words) {
+		b.words = append(b.words, make([]uint64, n-len(b.words))...)
+	}
+}
+
+// Set 将第 i 位置为 1
+func (b *BitSet) Set(i uint) {
+	b.grow(int(i>>6) + 1)
+	b.words[i>>6] |= 1 << (i & 63)
+}
+
+// Test 返回第 i 位是否为 1
+func (b *BitSet) Test(i uint) bool {
+	idx := int(i >> 6)
+	return idx < len(b.words) && b.words[idx]&(1<<(i&63)) != 0
+}
+
+// Flip 翻转第 i 位
+func (b *BitSet) Flip(i uint) {
+	b.grow(int(i>>6) + 1)
+	b.words[i>>6] ^= 1 << (i & 63)
+}
+
+// Count 返回为 1 的位的数量
+func (b *BitSet) Count() int {
+	cnt := 0
+	for _, w := range b.words {
+		cnt += bits.OnesCount64(w)
+	}
+	return cnt
+}
+
+// NextSet 返回大于等于 i 的第一个为 1 的位，没有的时候第二个返回值为 false
+func (b *BitSet) NextSet(i uint) (uint, bool) {
+	idx := int(i >> 6)
+	if idx >= len(b.words) {
+		return 0, false
+	}
+	if w := b.words[idx] >> (i & 63); w != 0 {
+		return i + uint(bits.TrailingZeros64(w)), true
+	}
+	for idx++; idx < len(b.words); idx++ {
+		if w := b.words[idx]; w != 0 {
+			return uint(idx)<<6 + uint(bits.TrailingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+// NextClear 返回大于等于 i 的第一个为 0 的位
+// 超出已分配范围的位都是 0，所以一定能找到
+func (b *BitSet) NextClear(i uint) uint {
+	idx := int(i >> 6)
+	if idx >= len(b.words) {
+		return i
+	}
+	if w := ^b.words[idx] >> (i & 63); w != 0 {
+		return i + uint(bits.TrailingZeros64(w))
+	}
+	for idx++; idx < len(b.words); idx++ {
+		if w := ^b.words[idx]; w != 0 {
+			return uint(idx)<<6 + uint(bits.TrailingZeros64(w))
+		}
+	}
+	return uint(len(b.words)) << 6
+}
+
+// rangeMasks 对 [lo, hi) 覆盖的每个字调用 fn，mask 标记了这个字中落在区间内的位
+// 调用方需要保证 lo < hi 并且对应的字已经分配
+func (b *BitSet) rangeMasks(lo, hi uint, fn func(w *uint64, mask uint64)) {
+	first, last := lo>>6, (hi-1)>>6
+	for i := first; i <= last; i++ {
+		mask := ^uint64(0)
+		if i == first {
+			mask &= ^uint64(0) << (lo & 63)
+		}
+		if i == last {
+			mask &= ^uint64(0) >> (63 - (hi-1)&63)
+		}
+		fn(&b.words[i], mask)
+	}
+}
+
+// SetRange 将 [lo, hi) 中的位全部置为 1
+func (b *BitSet) SetRange(lo, hi uint) {
+	if lo >= hi {
+		return
+	}
+	b.grow(wordsNeeded(hi))
+	b.rangeMasks(lo, hi, func(w *uint64, mask uint64) {
+		*w |= mask
+	})
+}
+
+// ClearRange 将 [lo, hi) 中的位全部置为 0
+func (b *BitSet) ClearRange(lo, hi uint) {
+	hi = b.clampHigh(hi)
+	if lo >= hi {
+		return
+	}
+	b.rangeMasks(lo, hi, func(w *uint64, mask uint64) {
+		*w &^= mask
+	})
+}
+
+// FlipRange 翻转 [lo, hi) 中的位
+func (b *BitSet) FlipRange(lo, hi uint) {
+	if lo >= hi {
+		return
+	}
+	b.grow(wordsNeeded(hi))
+	b.rangeMasks(lo, hi, func(w *uint64, mask uint64) {
+		*w ^= mask
+	})
+}
+
+// CountRange 返回 [lo, hi) 中为 1 的位的数量
+func (b *BitSet) CountRange(lo, hi uint) int {
+	hi = b.clampHigh(hi)
+	if lo >= hi {
+		return 0
+	}
+	cnt := 0
+	b.rangeMasks(lo, hi, func(w *uint64, mask uint64) {
+		cnt += bits.OnesCount64(*w & mask)
+	})
+	return cnt
+}
+
+// clampHigh 将区间的上界限制在已分配的范围内
+func (b *BitSet) clampHigh(hi uint) uint {
+	if limit := uint(len(b.words)) << 6; hi > limit {
+		return limit
+	}
+	return hi
+}
+
+// And 原地求交集
+func (b *BitSet) And(other *BitSet) {
+	n := len(b.words)
+	if len(other.words) < n {
+		n = len(other.words)
+	}
+	for i := 0; i < n; i++ {
+		b.words[i] &= other.words[i]
+	}
+	for i := n; i < len(b.words); i++ {
+		b.words[i] = 0
+	}
+}
+
+// Or 原地求并集
+func (b *BitSet) Or(other *BitSet) {
+	b.grow(len(other.words))
+	for i, w := range other.words {
+		b.words[i] |= w
+	}
+}
+
+// Xor 原地求对称差
+func (b *BitSet) Xor(other *BitSet) {
+	b.grow(len(other.words))
+	for i, w := range other.words {
+		b.words[i] ^= w
+	}
+}
+
+// AndNot 原地求差集，即删除 other 中的元素
+func (b *BitSet) AndNot(other *BitSet) {
+	n := len(b.words)
+	if len(other.words) < n {
+		n = len(other.words)
+	}
+	for i := 0; i < n; i++ {
+		b.words[i] &^= other.words[i]
+	}
+}
+
+func (b *BitSet) Add(key uint) {
+	b.Set(key)
+}
+
+// Delete 将第 key 位置为 0
+func (b *BitSet) Delete(key uint) {
+	if idx := int(key >> 6); idx < len(b.words) {
+		b.words[idx] &^= 1 << (key & 63)
+	}
+}
+
+func (b *BitSet) Exist(key uint) bool {
+	return b.Test(key)
+}
+
+// Keys 从小到大返回所有的元素
+func (b *BitSet) Keys() []uint {
+	res := make([]uint, 0, b.Count())
+	b.Range(func(key uint) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+func (b *BitSet) Len() int {
+	return b.Count()
+}
+
+// Range 从小到大遍历所有的元素
+func (b *BitSet) Range(fn func(key uint) bool) {
+	for i, w := range b.words {
+		for w != 0 {
+			tz := bits.TrailingZeros64(w)
+			if !fn(uint(i)<<6 + uint(tz)) {
+				return
+			}
+			w &= w - 1
+		}
+	}
+}
+
+// Clear 删除所有的元素，保留已经分配的内存
+func (b *BitSet) Clear() {
+	for i := range b.words {
+		b.words[i] = 0
+	}
+}
+
+func (b *BitSet) AddAll(keys ...uint) {
+	for _, key := range keys {
+		b.Set(key)
+	}
+}
+
+// Equal 判断两个集合中的元素是否相同，末尾多分配的 0 不影响结果
+func (b *BitSet) Equal(other Set[uint]) bool {
+	o, ok := other.(*BitSet)
+	if !ok {
+		return equalSet[uint](b, other)
+	}
+	x, y := b.trimmed(), o.trimmed()
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BitSet) newEmpty() Set[uint] {
+	return NewBitSet(0)
+}
+
+// trimmed 返回去掉末尾全 0 的字之后的切片
+func (b *BitSet) trimmed() []uint64 {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	return b.words[:n]
+}
+
+// MarshalBinary 将 BitSet 编码为小端序的 uint64 序列，末尾全 0 的字会被省略
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	words := b.trimmed()
+	data := make([]byte, len(words)*8)
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(data[i*8:], w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 的结果中恢复，原本的元素会被覆盖
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data)%8 != 0 {
+		return errBitSetInvalidData
+	}
+	words := make([]uint64, len(data)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	b.words = words
+	return nil
+}
+
+// MarshalJSON 将 MarshalBinary 的结果编码为 base64 字符串
+// 大的位图用数字数组表示会非常大，所以这里没有使用数组
+func (b *BitSet) MarshalJSON() ([]byte, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(data))
+}
+
+func (b *BitSet) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return err
+	}
+	return b.UnmarshalBinary(raw)
+}
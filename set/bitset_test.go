@@ -0,0 +1,210 @@
+package set
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Set[uint] = (*BitSet)(nil)
+
+func TestBitSet_Basic(t *testing.T) {
+	var b BitSet
+	assert.False(t, b.Test(0))
+	b.Set(0)
+	b.Set(63)
+	b.Set(64)
+	b.Set(1000)
+	assert.True(t, b.Test(63))
+	assert.True(t, b.Test(64))
+	assert.False(t, b.Test(65))
+	assert.False(t, b.Test(1<<20))
+	assert.Equal(t, 4, b.Count())
+	assert.Equal(t, []uint{0, 63, 64, 1000}, b.Keys())
+
+	b.Flip(63)
+	b.Flip(65)
+	assert.Equal(t, []uint{0, 64, 65, 1000}, b.Keys())
+
+	b.Delete(64)
+	b.Delete(1 << 20)
+	assert.Equal(t, []uint{0, 65, 1000}, b.Keys())
+	assert.Equal(t, 3, b.Len())
+
+	b.Clear()
+	assert.Equal(t, 0, b.Len())
+	assert.Empty(t, b.Keys())
+}
+
+func TestBitSet_Next(t *testing.T) {
+	b := NewBitSet(256)
+	b.AddAll(3, 64, 200)
+	testCases := []struct {
+		from      uint
+		wantSet   uint
+		wantOK    bool
+		wantClear uint
+	}{
+		{from: 0, wantSet: 3, wantOK: true, wantClear: 0},
+		{from: 3, wantSet: 3, wantOK: true, wantClear: 4},
+		{from: 4, wantSet: 64, wantOK: true, wantClear: 4},
+		{from: 64, wantSet: 64, wantOK: true, wantClear: 65},
+		{from: 65, wantSet: 200, wantOK: true, wantClear: 65},
+		{from: 201, wantOK: false, wantClear: 201},
+		{from: 1000, wantOK: false, wantClear: 1000},
+	}
+	for _, tc := range testCases {
+		got, ok := b.NextSet(tc.from)
+		assert.Equal(t, tc.wantOK, ok, "NextSet(%d)", tc.from)
+		if ok {
+			assert.Equal(t, tc.wantSet, got, "NextSet(%d)", tc.from)
+		}
+		assert.Equal(t, tc.wantClear, b.NextClear(tc.from), "NextClear(%d)", tc.from)
+	}
+
+	full := NewBitSet(128)
+	full.SetRange(0, 128)
+	assert.Equal(t, uint(128), full.NextClear(0))
+	assert.Equal(t, uint(128), full.NextClear(70))
+}
+
+func TestBitSet_Range(t *testing.T) {
+	testCases := []struct {
+		name   string
+		lo, hi uint
+	}{
+		{name: "empty", lo: 10, hi: 10},
+		{name: "inside one word", lo: 3, hi: 9},
+		{name: "whole word", lo: 64, hi: 128},
+		{name: "cross words", lo: 60, hi: 200},
+		{name: "word boundary", lo: 63, hi: 65},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBitSet(0)
+			b.SetRange(tc.lo, tc.hi)
+			var want []uint
+			for i := tc.lo; i < tc.hi; i++ {
+				want = append(want, i)
+			}
+			assert.Equal(t, len(want), b.Count())
+			assert.Equal(t, len(want), b.CountRange(0, 1000))
+			if len(want) > 0 {
+				assert.Equal(t, want, b.Keys())
+			}
+
+			b.FlipRange(tc.lo, tc.hi+1)
+			if tc.lo < tc.hi {
+				assert.Equal(t, []uint{tc.hi}, b.Keys())
+			}
+			b.SetRange(0, 300)
+			b.ClearRange(tc.lo, tc.hi)
+			assert.Equal(t, 300-len(want), b.Count())
+			assert.Equal(t, 0, b.CountRange(tc.lo, tc.hi))
+			// 超出已分配范围的清除不会分配内存
+			b.ClearRange(1000, 1<<30)
+			assert.Equal(t, 300-len(want), b.Count())
+		})
+	}
+}
+
+func TestBitSet_Bulk(t *testing.T) {
+	newBitSet := func(keys ...uint) *BitSet {
+		b := NewBitSet(0)
+		b.AddAll(keys...)
+		return b
+	}
+	testCases := []struct {
+		name string
+		op   func(a, b *BitSet)
+		a, b []uint
+		want []uint
+	}{
+		{name: "and", op: (*BitSet).And, a: []uint{1, 2, 300}, b: []uint{2, 3}, want: []uint{2}},
+		{name: "and longer", op: (*BitSet).And, a: []uint{1, 2}, b: []uint{2, 300}, want: []uint{2}},
+		{name: "or", op: (*BitSet).Or, a: []uint{1, 2}, b: []uint{2, 300}, want: []uint{1, 2, 300}},
+		{name: "xor", op: (*BitSet).Xor, a: []uint{1, 2}, b: []uint{2, 300}, want: []uint{1, 300}},
+		{name: "and not", op: (*BitSet).AndNot, a: []uint{1, 2, 300}, b: []uint{2, 3}, want: []uint{1, 300}},
+		{name: "and not shorter", op: (*BitSet).AndNot, a: []uint{1, 2}, b: []uint{2, 300}, want: []uint{1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newBitSet(tc.a...)
+			tc.op(a, newBitSet(tc.b...))
+			assert.Equal(t, tc.want, a.Keys())
+		})
+	}
+}
+
+func TestBitSet_Equal(t *testing.T) {
+	a := NewBitSet(0)
+	a.AddAll(1, 100)
+	b := NewBitSet(0)
+	b.AddAll(1, 100, 5000)
+	assert.False(t, a.Equal(b))
+	// 末尾多余的 0 不影响结果
+	b.Delete(5000)
+	assert.True(t, a.Equal(b))
+	assert.True(t, b.Equal(a))
+
+	m := NewMapSet[uint](2)
+	m.AddAll(1, 100)
+	assert.True(t, a.Equal(m))
+	m.Add(2)
+	assert.False(t, a.Equal(m))
+}
+
+func TestBitSet_Marshal(t *testing.T) {
+	b := NewBitSet(0)
+	b.AddAll(0, 7, 64, 129, 4096)
+	b.Delete(4096)
+
+	data, err := b.MarshalBinary()
+	require.NoError(t, err)
+	// 末尾全 0 的字不会被编码
+	assert.Len(t, data, 24)
+	var got BitSet
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.True(t, got.Equal(b))
+	assert.Equal(t, errBitSetInvalidData, got.UnmarshalBinary([]byte{1, 2, 3}))
+
+	js, err := json.Marshal(b)
+	require.NoError(t, err)
+	var fromJSON BitSet
+	require.NoError(t, json.Unmarshal(js, &fromJSON))
+	assert.Equal(t, []uint{0, 7, 64, 129}, fromJSON.Keys())
+	assert.Error(t, fromJSON.UnmarshalJSON([]byte(`[1,2]`)))
+	assert.Error(t, fromJSON.UnmarshalJSON([]byte(`"!!"`)))
+}
+
+func TestBitSet_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	b := NewBitSet(0)
+	m := NewMapSet[uint](0)
+	for i := 0; i < 5000; i++ {
+		key := uint(r.Intn(2000))
+		switch r.Intn(3) {
+		case 0:
+			b.Add(key)
+			m.Add(key)
+		case 1:
+			b.Delete(key)
+			m.Delete(key)
+		case 2:
+			if m.Exist(key) {
+				m.Delete(key)
+			} else {
+				m.Add(key)
+			}
+			b.Flip(key)
+		}
+	}
+	want := m.Keys()
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	assert.Equal(t, want, b.Keys())
+	assert.True(t, b.Equal(m))
+}
@@ -0,0 +1,162 @@
+package set
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// concurrentMapSetShards 是分片的数量，必须是 2 的幂
+const concurrentMapSetShards = 32
+
+type mapSetShard[T comparable] struct {
+	lock sync.RWMutex
+	m    map[T]struct{}
+}
+
+// ConcurrentMapSet 是并发安全的 MapSet
+// 元素按照哈希值分散到多个分片上，每个分片有自己的读写锁，不同分片上的操作互不阻塞
+// 哈希值使用 maphash.Comparable 计算，所以 == 相等的元素一定落在同一个分片上
+type ConcurrentMapSet[T comparable] struct {
+	seed   maphash.Seed
+	shards [concurrentMapSetShards]mapSetShard[T]
+}
+
+// NewConcurrentMapSet 创建 ConcurrentMapSet，size 是预计的元素数量
+func NewConcurrentMapSet[T comparable](size int) *ConcurrentMapSet[T] {
+	s := &ConcurrentMapSet[T]{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].m = make(map[T]struct{}, size/concurrentMapSetShards)
+	}
+	return s
+}
+
+func (s *ConcurrentMapSet[T]) shard(key T) *mapSetShard[T] {
+	return &s.shards[maphash.Comparable(s.seed, key)&(concurrentMapSetShards-1)]
+}
+
+func (s *ConcurrentMapSet[T]) Add(key T) {
+	s.AddIfAbsent(key)
+}
+
+// AddIfAbsent 原子地添加元素，返回是否添加成功，元素已经存在的时候返回 false
+func (s *ConcurrentMapSet[T]) AddIfAbsent(key T) bool {
+	sd := s.shard(key)
+	sd.lock.Lock()
+	defer sd.lock.Unlock()
+	if _, ok := sd.m[key]; ok {
+		return false
+	}
+	sd.m[key] = struct{}{}
+	return true
+}
+
+func (s *ConcurrentMapSet[T]) Delete(key T) {
+	s.DeleteIfPresent(key)
+}
+
+// DeleteIfPresent 原子地删除元素，返回是否删除成功，元素不存在的时候返回 false
+func (s *ConcurrentMapSet[T]) DeleteIfPresent(key T) bool {
+	sd := s.shard(key)
+	sd.lock.Lock()
+	defer sd.lock.Unlock()
+	if _, ok := sd.m[key]; !ok {
+		return false
+	}
+	delete(sd.m, key)
+	return true
+}
+
+func (s *ConcurrentMapSet[T]) Exist(key T) bool {
+	sd := s.shard(key)
+	sd.lock.RLock()
+	defer sd.lock.RUnlock()
+	_, ok := sd.m[key]
+	return ok
+}
+
+// Keys 返回某一时刻所有元素的快照，顺序不固定
+// 为了得到一致的快照，会同时持有所有分片的读锁
+func (s *ConcurrentMapSet[T]) Keys() []T {
+	s.rLockAll()
+	defer s.rUnlockAll()
+	res := make([]T, 0, s.len())
+	for i := range s.shards {
+		for key := range s.shards[i].m {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// Len 返回某一时刻元素的数量
+func (s *ConcurrentMapSet[T]) Len() int {
+	s.rLockAll()
+	defer s.rUnlockAll()
+	return s.len()
+}
+
+func (s *ConcurrentMapSet[T]) len() int {
+	n := 0
+	for i := range s.shards {
+		n += len(s.shards[i].m)
+	}
+	return n
+}
+
+// Range 逐个分片遍历元素，顺序不固定
+// 遍历是弱一致的：每个分片遍历的是它自己的快照，遍历过程中的修改可能看得到也可能看不到，
+// fn 执行的时候不持有锁，所以 fn 中可以修改这个集合
+func (s *ConcurrentMapSet[T]) Range(fn func(key T) bool) {
+	for i := range s.shards {
+		sd := &s.shards[i]
+		sd.lock.RLock()
+		keys := make([]T, 0, len(sd.m))
+		for key := range sd.m {
+			keys = append(keys, key)
+		}
+		sd.lock.RUnlock()
+		for _, key := range keys {
+			if !fn(key) {
+				return
+			}
+		}
+	}
+}
+
+func (s *ConcurrentMapSet[T]) Clear() {
+	for i := range s.shards {
+		sd := &s.shards[i]
+		sd.lock.Lock()
+		sd.m = make(map[T]struct{})
+		sd.lock.Unlock()
+	}
+}
+
+// AddAll 添加多个元素，每个元素的添加是原子的，但是整体不是
+func (s *ConcurrentMapSet[T]) AddAll(keys ...T) {
+	for _, key := range keys {
+		s.AddIfAbsent(key)
+	}
+}
+
+// Equal 判断两个集合中的元素是否相同，在并发修改的时候结果只反映某一个中间状态
+func (s *ConcurrentMapSet[T]) Equal(other Set[T]) bool {
+	return equalSet[T](s, other)
+}
+
+func (s *ConcurrentMapSet[T]) newEmpty() Set[T] {
+	return NewConcurrentMapSet[T](0)
+}
+
+// rLockAll 按照固定的顺序获得所有分片的读锁，避免死锁
+func (s *ConcurrentMapSet[T]) rLockAll() {
+	for i := range s.shards {
+		s.shards[i].lock.RLock()
+	}
+}
+
+func (s *ConcurrentMapSet[T]) rUnlockAll() {
+	for i := range s.shards {
+		s.shards[i].lock.RUnlock()
+	}
+}
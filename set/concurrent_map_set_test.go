@@ -0,0 +1,120 @@
+package set
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentMapSet(t *testing.T) {
+	s := NewConcurrentMapSet[int](10)
+	assert.True(t, s.AddIfAbsent(1))
+	assert.False(t, s.AddIfAbsent(1))
+	s.AddAll(2, 3, 4)
+	assert.Equal(t, 4, s.Len())
+	assert.True(t, s.Exist(3))
+
+	assert.True(t, s.DeleteIfPresent(3))
+	assert.False(t, s.DeleteIfPresent(3))
+	assert.False(t, s.Exist(3))
+
+	keys := s.Keys()
+	sort.Ints(keys)
+	assert.Equal(t, []int{1, 2, 4}, keys)
+	other := NewMapSet[int](3)
+	other.AddAll(1, 2, 4)
+	assert.True(t, s.Equal(other))
+
+	cnt := 0
+	s.Range(func(key int) bool {
+		cnt++
+		return false
+	})
+	assert.Equal(t, 1, cnt)
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+	assert.Empty(t, s.Keys())
+}
+
+// 元素的哈希值必须和 == 的语义一致，否则相等的元素会落在不同的分片上
+func TestConcurrentMapSet_KeySemantics(t *testing.T) {
+	t.Run("float zero", func(t *testing.T) {
+		s := NewConcurrentMapSet[float64](0)
+		s.Add(0.0)
+		assert.False(t, s.AddIfAbsent(math.Copysign(0, -1)))
+		assert.True(t, s.Exist(math.Copysign(0, -1)))
+		assert.Equal(t, 1, s.Len())
+	})
+
+	t.Run("pointer in interface", func(t *testing.T) {
+		type user struct {
+			Name string
+		}
+		s := NewConcurrentMapSet[any](0)
+		p := &user{Name: "Tom"}
+		s.Add(p)
+		// 修改指针指向的内容不会影响元素
+		p.Name = "Jerry"
+		assert.True(t, s.Exist(p))
+		assert.False(t, s.Exist(&user{Name: "Jerry"}))
+		assert.False(t, s.AddIfAbsent(p))
+		assert.True(t, s.DeleteIfPresent(p))
+		assert.Equal(t, 0, s.Len())
+	})
+}
+
+func TestConcurrentMapSet_RangeModify(t *testing.T) {
+	s := NewConcurrentMapSet[int](100)
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	// fn 中删除元素不会死锁
+	s.Range(func(key int) bool {
+		s.Delete(key)
+		return true
+	})
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestConcurrentMapSet_AddIfAbsentConcurrent(t *testing.T) {
+	s := NewConcurrentMapSet[int](0)
+	const goroutines, keys = 8, 1000
+	var added int64
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				if s.AddIfAbsent(k) {
+					atomic.AddInt64(&added, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// 每个元素只有一个 goroutine 能添加成功
+	assert.Equal(t, int64(keys), added)
+	assert.Equal(t, keys, s.Len())
+
+	var deleted int64
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				if s.DeleteIfPresent(k) {
+					atomic.AddInt64(&deleted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(keys), deleted)
+	assert.Equal(t, 0, s.Len())
+}
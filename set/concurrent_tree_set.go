@@ -0,0 +1,126 @@
+package set
+
+import (
+	"sync"
+
+	"github.com/WeiXinao/xkit"
+)
+
+// ConcurrentTreeSet 用读写锁封装了对 TreeSet 的操作
+// 达到线程安全的目标
+type ConcurrentTreeSet[T any] struct {
+	lock    sync.RWMutex
+	treeSet *TreeSet[T]
+}
+
+func NewConcurrentTreeSet[T any](compare xkit.Comparator[T]) (*ConcurrentTreeSet[T], error) {
+	treeSet, err := NewTreeSet[T](compare)
+	if err != nil {
+		return nil, err
+	}
+	return &ConcurrentTreeSet[T]{
+		treeSet: treeSet,
+	}, nil
+}
+
+func (s *ConcurrentTreeSet[T]) Add(key T) {
+	s.AddIfAbsent(key)
+}
+
+// AddIfAbsent 原子地添加元素，返回是否添加成功，元素已经存在的时候返回 false
+func (s *ConcurrentTreeSet[T]) AddIfAbsent(key T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.treeSet.Exist(key) {
+		return false
+	}
+	s.treeSet.Add(key)
+	return true
+}
+
+func (s *ConcurrentTreeSet[T]) Delete(key T) {
+	s.DeleteIfPresent(key)
+}
+
+// DeleteIfPresent 原子地删除元素，返回是否删除成功，元素不存在的时候返回 false
+func (s *ConcurrentTreeSet[T]) DeleteIfPresent(key T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.treeSet.Exist(key) {
+		return false
+	}
+	s.treeSet.Delete(key)
+	return true
+}
+
+func (s *ConcurrentTreeSet[T]) Exist(key T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.Exist(key)
+}
+
+// Keys 返回某一时刻所有元素的快照，从小到大排列
+func (s *ConcurrentTreeSet[T]) Keys() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.Keys()
+}
+
+func (s *ConcurrentTreeSet[T]) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.Len()
+}
+
+// Range 从小到大遍历调用时的快照，fn 执行的时候不持有锁，所以 fn 中可以修改这个集合
+func (s *ConcurrentTreeSet[T]) Range(fn func(key T) bool) {
+	for _, key := range s.Keys() {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (s *ConcurrentTreeSet[T]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.treeSet.Clear()
+}
+
+// AddAll 原子地添加多个元素
+func (s *ConcurrentTreeSet[T]) AddAll(keys ...T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.treeSet.AddAll(keys...)
+}
+
+// Equal 判断两个集合中的元素是否相同，在并发修改的时候结果只反映某一个中间状态
+func (s *ConcurrentTreeSet[T]) Equal(other Set[T]) bool {
+	return equalSet[T](s, other)
+}
+
+func (s *ConcurrentTreeSet[T]) newEmpty() Set[T] {
+	res, _ := NewConcurrentTreeSet[T](s.treeSet.compare)
+	return res
+}
+
+// Rank 返回小于 key 的元素数量，key 本身不需要存在
+func (s *ConcurrentTreeSet[T]) Rank(key T) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.Rank(key)
+}
+
+// Select 返回从小到大排序之后下标为 index 的元素，下标从 0 开始
+func (s *ConcurrentTreeSet[T]) Select(index int) (T, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.Select(index)
+}
+
+// CountRange 返回落在 [lo, hi) 中的元素数量
+func (s *ConcurrentTreeSet[T]) CountRange(lo, hi T) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.treeSet.CountRange(lo, hi)
+}
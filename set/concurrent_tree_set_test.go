@@ -0,0 +1,69 @@
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WeiXinao/xkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrentTreeSet(t *testing.T) {
+	_, err := NewConcurrentTreeSet[int](nil)
+	assert.EqualError(t, err, "xkit: Comparator不能为nil")
+}
+
+func TestConcurrentTreeSet(t *testing.T) {
+	s, err := NewConcurrentTreeSet[int](xkit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	assert.True(t, s.AddIfAbsent(3))
+	assert.False(t, s.AddIfAbsent(3))
+	s.AddAll(1, 4, 2)
+	assert.Equal(t, []int{1, 2, 3, 4}, s.Keys())
+	assert.Equal(t, 4, s.Len())
+
+	assert.True(t, s.DeleteIfPresent(2))
+	assert.False(t, s.DeleteIfPresent(2))
+	assert.False(t, s.Exist(2))
+
+	var got []int
+	s.Range(func(key int) bool {
+		got = append(got, key)
+		// fn 中修改集合不会死锁，也不影响本次遍历
+		s.Add(key + 10)
+		return true
+	})
+	assert.Equal(t, []int{1, 3, 4}, got)
+	assert.Equal(t, []int{1, 3, 4, 11, 13, 14}, s.Keys())
+
+	other := NewMapSet[int](6)
+	other.AddAll(1, 3, 4, 11, 13, 14)
+	assert.True(t, s.Equal(other))
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestConcurrentTreeSet_AddIfAbsentConcurrent(t *testing.T) {
+	s, err := NewConcurrentTreeSet[int](xkit.ComparatorRealNumber[int])
+	require.NoError(t, err)
+	const goroutines, keys = 8, 1000
+	var added int64
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				if s.AddIfAbsent(k) {
+					atomic.AddInt64(&added, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(keys), added)
+	assert.Equal(t, keys, s.Len())
+}
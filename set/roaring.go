@@ -0,0 +1,497 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sort"
+
+	"github.com/WeiXinao/xkit/internal/errs"
+)
+
+var errRoaringInvalidData = errors.New("xkit: Roaring 序列化数据格式错误")
+
+const (
+	// roaringSerialCookieNoRun 和 roaringSerialCookie 是标准序列化格式开头的标记，
+	// 后者表示存在 runContainer
+	roaringSerialCookieNoRun = 12346
+	roaringSerialCookie      = 12347
+	// roaringNoOffsetThreshold 存在 runContainer 并且容器数量少于它的时候，序列化格式中没有偏移量
+	roaringNoOffsetThreshold = 4
+)
+
+// Roaring 是压缩的 uint32 位图集合
+// 元素按照高 16 位分桶，每个桶根据元素的分布使用有序数组、位图或者连续区间保存低 16 位，
+// 在稀疏的大范围 ID 上比 BitSet 节省内存，同时保留了位图运算的速度
+// 零值可以直接使用
+//
+// 序列化格式和 Roaring 的其它语言实现通用，
+// 见 https://github.com/RoaringBitmap/RoaringFormatSpec
+type Roaring struct {
+	// keys 是有序的高 16 位，containers 和 keys 一一对应
+	keys       []uint16
+	containers []container
+}
+
+func NewRoaring() *Roaring {
+	return &Roaring{}
+}
+
+func (r *Roaring) index(hi uint16) (int, bool) {
+	i := sort.Search(len(r.keys), func(i int) bool {
+		return r.keys[i] >= hi
+	})
+	return i, i < len(r.keys) && r.keys[i] == hi
+}
+
+func (r *Roaring) Add(key uint32) {
+	hi, lo := uint16(key>>16), uint16(key)
+	i, ok := r.index(hi)
+	if ok {
+		r.containers[i] = r.containers[i].add(lo)
+		return
+	}
+	r.keys = append(r.keys, 0)
+	copy(r.keys[i+1:], r.keys[i:])
+	r.keys[i] = hi
+	r.containers = append(r.containers, nil)
+	copy(r.containers[i+1:], r.containers[i:])
+	r.containers[i] = &arrayContainer{content: []uint16{lo}}
+}
+
+func (r *Roaring) Delete(key uint32) {
+	i, ok := r.index(uint16(key >> 16))
+	if !ok {
+		return
+	}
+	c := r.containers[i].remove(uint16(key))
+	if c.cardinality() > 0 {
+		r.containers[i] = c
+		return
+	}
+	r.keys = append(r.keys[:i], r.keys[i+1:]...)
+	r.containers = append(r.containers[:i], r.containers[i+1:]...)
+}
+
+func (r *Roaring) Exist(key uint32) bool {
+	i, ok := r.index(uint16(key >> 16))
+	return ok && r.containers[i].contains(uint16(key))
+}
+
+// Keys 从小到大返回所有的元素
+func (r *Roaring) Keys() []uint32 {
+	res := make([]uint32, 0, r.Len())
+	r.Range(func(key uint32) bool {
+		res = append(res, key)
+		return true
+	})
+	return res
+}
+
+func (r *Roaring) Len() int {
+	n := 0
+	for _, c := range r.containers {
+		n += c.cardinality()
+	}
+	return n
+}
+
+// Range 从小到大遍历所有的元素
+func (r *Roaring) Range(fn func(key uint32) bool) {
+	for i, c := range r.containers {
+		base := uint32(r.keys[i]) << 16
+		if !c.iterate(func(x uint16) bool {
+			return fn(base | uint32(x))
+		}) {
+			return
+		}
+	}
+}
+
+func (r *Roaring) Clear() {
+	r.keys = nil
+	r.containers = nil
+}
+
+func (r *Roaring) AddAll(keys ...uint32) {
+	for _, key := range keys {
+		r.Add(key)
+	}
+}
+
+// Equal 判断两个集合中的元素是否相同，和容器的类型无关
+func (r *Roaring) Equal(other Set[uint32]) bool {
+	o, ok := other.(*Roaring)
+	if !ok {
+		return equalSet[uint32](r, other)
+	}
+	if len(r.keys) != len(o.keys) {
+		return false
+	}
+	for i := range r.keys {
+		if r.keys[i] != o.keys[i] || !containerEqual(r.containers[i], o.containers[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Roaring) newEmpty() Set[uint32] {
+	return NewRoaring()
+}
+
+func (r *Roaring) Clone() *Roaring {
+	res := &Roaring{
+		keys:       append([]uint16(nil), r.keys...),
+		containers: make([]container, len(r.containers)),
+	}
+	for i, c := range r.containers {
+		res.containers[i] = c.clone()
+	}
+	return res
+}
+
+// Rank 返回小于 key 的元素数量，key 本身不需要存在
+func (r *Roaring) Rank(key uint32) int {
+	hi := uint16(key >> 16)
+	n := 0
+	for i, k := range r.keys {
+		if k > hi {
+			break
+		}
+		if k < hi {
+			n += r.containers[i].cardinality()
+		} else {
+			n += r.containers[i].rank(uint16(key))
+		}
+	}
+	return n
+}
+
+// Select 返回从小到大排序之后下标为 index 的元素，下标从 0 开始
+// 下标超出范围会返回错误
+func (r *Roaring) Select(index int) (uint32, error) {
+	if index >= 0 {
+		i := index
+		for j, c := range r.containers {
+			if card := c.cardinality(); i >= card {
+				i -= card
+				continue
+			}
+			return uint32(r.keys[j])<<16 | uint32(r.containers[j].selectAt(i)), nil
+		}
+	}
+	return 0, errs.NewErrIndexOutOfRange(r.Len(), index)
+}
+
+// RunOptimize 把每个容器转换为占用空间最小的形式，
+// 元素连成片的时候会使用连续区间保存，通常在批量写入之后调用
+func (r *Roaring) RunOptimize() {
+	for i, c := range r.containers {
+		r.containers[i] = toEfficient(c)
+	}
+}
+
+// And 原地求交集
+func (r *Roaring) And(other *Roaring) {
+	r.merge(other, containerAnd, false, false)
+}
+
+// Or 原地求并集
+func (r *Roaring) Or(other *Roaring) {
+	r.merge(other, containerOr, true, true)
+}
+
+// Xor 原地求对称差
+func (r *Roaring) Xor(other *Roaring) {
+	r.merge(other, containerXor, true, true)
+}
+
+// AndNot 原地求差集，即删除 other 中的元素
+func (r *Roaring) AndNot(other *Roaring) {
+	r.merge(other, containerAndNot, true, false)
+}
+
+// merge 按照高 16 位归并两个位图
+// 只在一边出现的桶根据 keepA 和 keepB 决定是否保留，两边都有的桶使用 fn 计算，结果为空的桶会被删除
+func (r *Roaring) merge(other *Roaring, fn func(a, b container) container, keepA, keepB bool) {
+	keys := make([]uint16, 0, len(r.keys)+len(other.keys))
+	containers := make([]container, 0, len(r.keys)+len(other.keys))
+	i, j := 0, 0
+	for i < len(r.keys) || j < len(other.keys) {
+		var c container
+		var key uint16
+		switch {
+		case j == len(other.keys) || (i < len(r.keys) && r.keys[i] < other.keys[j]):
+			key = r.keys[i]
+			if keepA {
+				c = r.containers[i]
+			}
+			i++
+		case i == len(r.keys) || other.keys[j] < r.keys[i]:
+			key = other.keys[j]
+			if keepB {
+				c = other.containers[j].clone()
+			}
+			j++
+		default:
+			key = r.keys[i]
+			c = fn(r.containers[i], other.containers[j])
+			i++
+			j++
+		}
+		if c != nil && c.cardinality() > 0 {
+			keys = append(keys, key)
+			containers = append(containers, c)
+		}
+	}
+	r.keys, r.containers = keys, containers
+}
+
+// MarshalBinary 按照 Roaring 的标准格式序列化，所有的整数都是小端序：
+//   - 存在 runContainer 的时候，开头是 4 字节的 roaringSerialCookie | (容器数量 - 1) << 16，
+//     随后是标记每个容器是否为 runContainer 的位图；否则开头是 4 字节的 roaringSerialCookieNoRun 和 4 字节的容器数量
+//   - 每个容器 2 字节的高 16 位和 2 字节的元素数量减一
+//   - 不存在 runContainer 或者容器数量不少于 roaringNoOffsetThreshold 的时候，每个容器 4 字节的偏移量
+//   - 每个容器的内容：runContainer 是 2 字节的区间数量和每个区间的起点、长度减一；
+//     元素多于 arrayContainerMaxSize 的是 8192 字节的位图；其余的是有序的元素
+func (r *Roaring) MarshalBinary() ([]byte, error) {
+	n := len(r.containers)
+	hasRun := false
+	for _, c := range r.containers {
+		if isRunContainer(c) {
+			hasRun = true
+			break
+		}
+	}
+
+	size := 8 + 4*n
+	if hasRun {
+		size = 4 + (n+7)/8 + 4*n
+	}
+	withOffsets := !hasRun || n >= roaringNoOffsetThreshold
+	if withOffsets {
+		size += 4 * n
+	}
+	offsets := make([]uint32, n)
+	for i, c := range r.containers {
+		offsets[i] = uint32(size)
+		size += serializedSize(c)
+	}
+
+	data := make([]byte, 0, size)
+	if hasRun {
+		data = binary.LittleEndian.AppendUint32(data, roaringSerialCookie|uint32(n-1)<<16)
+		flags := make([]byte, (n+7)/8)
+		for i, c := range r.containers {
+			if isRunContainer(c) {
+				flags[i/8] |= 1 << (i % 8)
+			}
+		}
+		data = append(data, flags...)
+	} else {
+		data = binary.LittleEndian.AppendUint32(data, roaringSerialCookieNoRun)
+		data = binary.LittleEndian.AppendUint32(data, uint32(n))
+	}
+	for i, c := range r.containers {
+		data = binary.LittleEndian.AppendUint16(data, r.keys[i])
+		data = binary.LittleEndian.AppendUint16(data, uint16(c.cardinality()-1))
+	}
+	if withOffsets {
+		for _, off := range offsets {
+			data = binary.LittleEndian.AppendUint32(data, off)
+		}
+	}
+	for _, c := range r.containers {
+		data = appendContainer(data, c)
+	}
+	return data, nil
+}
+
+func serializedSize(c container) int {
+	if rc, ok := c.(*runContainer); ok {
+		return runContainerSize(len(rc.runs))
+	}
+	if card := c.cardinality(); card <= arrayContainerMaxSize {
+		return arrayContainerSize(card)
+	}
+	return bitmapContainerSize
+}
+
+func appendContainer(data []byte, c container) []byte {
+	if rc, ok := c.(*runContainer); ok {
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(rc.runs)))
+		for _, run := range rc.runs {
+			data = binary.LittleEndian.AppendUint16(data, run.start)
+			data = binary.LittleEndian.AppendUint16(data, run.last-run.start)
+		}
+		return data
+	}
+	if c.cardinality() <= arrayContainerMaxSize {
+		c.iterate(func(x uint16) bool {
+			data = binary.LittleEndian.AppendUint16(data, x)
+			return true
+		})
+		return data
+	}
+	b, ok := c.(*bitmapContainer)
+	if !ok {
+		b = c.toBitmap()
+	}
+	for _, w := range b.bits.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data
+}
+
+// UnmarshalBinary 从 Roaring 的标准格式中恢复，原本的元素会被覆盖
+// 数据不完整或者不合法的时候返回错误，并且不会修改原本的元素
+func (r *Roaring) UnmarshalBinary(data []byte) error {
+	rd := &roaringReader{data: data}
+	cookie, ok := rd.uint32()
+	if !ok {
+		return errRoaringInvalidData
+	}
+	var n int
+	var runFlags []byte
+	switch {
+	case cookie&0xFFFF == roaringSerialCookie:
+		n = int(cookie>>16) + 1
+		if runFlags, ok = rd.bytes((n + 7) / 8); !ok {
+			return errRoaringInvalidData
+		}
+	case cookie == roaringSerialCookieNoRun:
+		size, ok := rd.uint32()
+		if !ok || size > 1<<16 {
+			return errRoaringInvalidData
+		}
+		n = int(size)
+	default:
+		return errRoaringInvalidData
+	}
+
+	header, ok := rd.bytes(4 * n)
+	if !ok {
+		return errRoaringInvalidData
+	}
+	// 容器是紧挨着存放的，所以顺序读取的时候用不到偏移量
+	if runFlags == nil || n >= roaringNoOffsetThreshold {
+		if _, ok = rd.bytes(4 * n); !ok {
+			return errRoaringInvalidData
+		}
+	}
+
+	keys := make([]uint16, n)
+	containers := make([]container, n)
+	for i := 0; i < n; i++ {
+		keys[i] = binary.LittleEndian.Uint16(header[4*i:])
+		if i > 0 && keys[i] <= keys[i-1] {
+			return errRoaringInvalidData
+		}
+		card := int(binary.LittleEndian.Uint16(header[4*i+2:])) + 1
+		var c container
+		switch {
+		case runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0:
+			c, ok = rd.runContainer()
+		case card > arrayContainerMaxSize:
+			c, ok = rd.bitmapContainer()
+		default:
+			c, ok = rd.arrayContainer(card)
+		}
+		if !ok || c.cardinality() != card {
+			return errRoaringInvalidData
+		}
+		containers[i] = c
+	}
+	if len(rd.data) != rd.off {
+		return errRoaringInvalidData
+	}
+	r.keys, r.containers = keys, containers
+	return nil
+}
+
+// roaringReader 按顺序读取序列化数据，数据不足的时候返回 false
+type roaringReader struct {
+	data []byte
+	off  int
+}
+
+func (rd *roaringReader) bytes(n int) ([]byte, bool) {
+	if n < 0 || n > len(rd.data)-rd.off {
+		return nil, false
+	}
+	res := rd.data[rd.off : rd.off+n]
+	rd.off += n
+	return res, true
+}
+
+func (rd *roaringReader) uint16() (uint16, bool) {
+	b, ok := rd.bytes(2)
+	if !ok {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(b), true
+}
+
+func (rd *roaringReader) uint32() (uint32, bool) {
+	b, ok := rd.bytes(4)
+	if !ok {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(b), true
+}
+
+// arrayContainer 读取 card 个严格递增的元素
+func (rd *roaringReader) arrayContainer(card int) (container, bool) {
+	b, ok := rd.bytes(2 * card)
+	if !ok {
+		return nil, false
+	}
+	content := make([]uint16, card)
+	for i := range content {
+		content[i] = binary.LittleEndian.Uint16(b[2*i:])
+		if i > 0 && content[i] <= content[i-1] {
+			return nil, false
+		}
+	}
+	return &arrayContainer{content: content}, true
+}
+
+func (rd *roaringReader) bitmapContainer() (container, bool) {
+	b, ok := rd.bytes(bitmapContainerSize)
+	if !ok {
+		return nil, false
+	}
+	c := newBitmapContainer()
+	for i := range c.bits.words {
+		w := binary.LittleEndian.Uint64(b[8*i:])
+		c.bits.words[i] = w
+		c.card += bits.OnesCount64(w)
+	}
+	return c, true
+}
+
+// runContainer 读取有序并且互不重叠的区间，相邻的区间也是合法的
+func (rd *roaringReader) runContainer() (container, bool) {
+	numRuns, ok := rd.uint16()
+	if !ok {
+		return nil, false
+	}
+	b, ok := rd.bytes(4 * int(numRuns))
+	if !ok {
+		return nil, false
+	}
+	runs := make([]runInterval, numRuns)
+	for i := range runs {
+		start := binary.LittleEndian.Uint16(b[4*i:])
+		length := binary.LittleEndian.Uint16(b[4*i+2:])
+		if int(start)+int(length) > 0xFFFF {
+			return nil, false
+		}
+		if i > 0 && start <= runs[i-1].last {
+			return nil, false
+		}
+		runs[i] = runInterval{start: start, last: start + length}
+	}
+	return &runContainer{runs: runs}, true
+}
@@ -0,0 +1,638 @@
+package set
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/WeiXinao/xkit"
+)
+
+const (
+	// arrayContainerMaxSize 是 arrayContainer 最多容纳的元素数量，
+	// 超过之后 arrayContainer 占用的空间会比 bitmapContainer 更大
+	arrayContainerMaxSize = 4096
+	// bitmapContainerWords 是 bitmapContainer 中 uint64 的数量，刚好覆盖 65536 个值
+	bitmapContainerWords = 1024
+)
+
+// container 保存高 16 位相同的元素的低 16 位
+// 会改变元素的方法可能会把自己转换为另外一种容器，调用方需要使用返回值替换原本的容器
+type container interface {
+	contains(x uint16) bool
+	add(x uint16) container
+	remove(x uint16) container
+	cardinality() int
+	// rank 返回小于 x 的元素数量
+	rank(x uint16) int
+	// selectAt 返回从小到大下标为 i 的元素，调用方保证 i 没有越界
+	selectAt(i int) uint16
+	// iterate 从小到大遍历，fn 返回 false 的时候中断并且返回 false
+	iterate(fn func(x uint16) bool) bool
+	clone() container
+	// toBitmap 返回一个新的 bitmapContainer，调用方可以随意修改
+	toBitmap() *bitmapContainer
+	// numRuns 返回连续区间的数量
+	numRuns() int
+}
+
+// arrayContainer 是有序的数组，适合稀疏的场景
+type arrayContainer struct {
+	content []uint16
+}
+
+func (a *arrayContainer) search(x uint16) (int, bool) {
+	i := sort.Search(len(a.content), func(i int) bool {
+		return a.content[i] >= x
+	})
+	return i, i < len(a.content) && a.content[i] == x
+}
+
+func (a *arrayContainer) contains(x uint16) bool {
+	_, ok := a.search(x)
+	return ok
+}
+
+func (a *arrayContainer) add(x uint16) container {
+	i, ok := a.search(x)
+	if ok {
+		return a
+	}
+	if len(a.content) >= arrayContainerMaxSize {
+		return a.toBitmap().add(x)
+	}
+	a.content = append(a.content, 0)
+	copy(a.content[i+1:], a.content[i:])
+	a.content[i] = x
+	return a
+}
+
+func (a *arrayContainer) remove(x uint16) container {
+	if i, ok := a.search(x); ok {
+		a.content = append(a.content[:i], a.content[i+1:]...)
+	}
+	return a
+}
+
+func (a *arrayContainer) cardinality() int {
+	return len(a.content)
+}
+
+func (a *arrayContainer) rank(x uint16) int {
+	i, _ := a.search(x)
+	return i
+}
+
+func (a *arrayContainer) selectAt(i int) uint16 {
+	return a.content[i]
+}
+
+func (a *arrayContainer) iterate(fn func(x uint16) bool) bool {
+	for _, x := range a.content {
+		if !fn(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *arrayContainer) clone() container {
+	return &arrayContainer{content: append([]uint16(nil), a.content...)}
+}
+
+func (a *arrayContainer) toBitmap() *bitmapContainer {
+	b := newBitmapContainer()
+	for _, x := range a.content {
+		b.bits.Set(uint(x))
+	}
+	b.card = len(a.content)
+	return b
+}
+
+func (a *arrayContainer) numRuns() int {
+	if len(a.content) == 0 {
+		return 0
+	}
+	n := 1
+	for i := 1; i < len(a.content); i++ {
+		if a.content[i] != a.content[i-1]+1 {
+			n++
+		}
+	}
+	return n
+}
+
+// filter 返回 a 中 other.contains 结果为 keep 的元素
+func (a *arrayContainer) filter(other container, keep bool) *arrayContainer {
+	res := make([]uint16, 0, len(a.content))
+	for _, x := range a.content {
+		if other.contains(x) == keep {
+			res = append(res, x)
+		}
+	}
+	return &arrayContainer{content: res}
+}
+
+// merge 归并两个有序数组，fn 决定元素是否保留，结果超过 arrayContainerMaxSize 的时候转换为 bitmapContainer
+func (a *arrayContainer) merge(other *arrayContainer, fn func(inA, inB bool) bool) container {
+	res := make([]uint16, 0, len(a.content)+len(other.content))
+	mergeSorted(a.content, other.content, xkit.ComparatorRealNumber[uint16], func(x uint16, inA, inB bool) bool {
+		if fn(inA, inB) {
+			res = append(res, x)
+		}
+		return true
+	})
+	c := &arrayContainer{content: res}
+	if len(res) > arrayContainerMaxSize {
+		return c.toBitmap()
+	}
+	return c
+}
+
+// bitmapContainer 是 65536 位的位图，适合稠密的场景
+type bitmapContainer struct {
+	bits BitSet
+	card int
+}
+
+func newBitmapContainer() *bitmapContainer {
+	return &bitmapContainer{
+		bits: BitSet{words: make([]uint64, bitmapContainerWords)},
+	}
+}
+
+func (b *bitmapContainer) contains(x uint16) bool {
+	return b.bits.Test(uint(x))
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	if !b.bits.Test(uint(x)) {
+		b.bits.Set(uint(x))
+		b.card++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	if b.bits.Test(uint(x)) {
+		b.bits.Delete(uint(x))
+		b.card--
+		if b.card <= arrayContainerMaxSize {
+			return b.toArray()
+		}
+	}
+	return b
+}
+
+func (b *bitmapContainer) cardinality() int {
+	return b.card
+}
+
+func (b *bitmapContainer) rank(x uint16) int {
+	return b.bits.CountRange(0, uint(x))
+}
+
+func (b *bitmapContainer) selectAt(i int) uint16 {
+	for idx, w := range b.bits.words {
+		cnt := bits.OnesCount64(w)
+		if i >= cnt {
+			i -= cnt
+			continue
+		}
+		for ; i > 0; i-- {
+			w &= w - 1
+		}
+		return uint16(idx<<6 + bits.TrailingZeros64(w))
+	}
+	return 0
+}
+
+func (b *bitmapContainer) iterate(fn func(x uint16) bool) bool {
+	res := true
+	b.bits.Range(func(key uint) bool {
+		res = fn(uint16(key))
+		return res
+	})
+	return res
+}
+
+func (b *bitmapContainer) clone() container {
+	return b.toBitmap()
+}
+
+func (b *bitmapContainer) toBitmap() *bitmapContainer {
+	return &bitmapContainer{
+		bits: BitSet{words: append([]uint64(nil), b.bits.words...)},
+		card: b.card,
+	}
+}
+
+func (b *bitmapContainer) toArray() *arrayContainer {
+	res := make([]uint16, 0, b.card)
+	b.iterate(func(x uint16) bool {
+		res = append(res, x)
+		return true
+	})
+	return &arrayContainer{content: res}
+}
+
+func (b *bitmapContainer) numRuns() int {
+	n := 0
+	// prev 是上一个字的最高位，用于判断区间是否跨越了两个字
+	var prev uint64
+	for _, w := range b.bits.words {
+		n += bits.OnesCount64(w &^ (w<<1 | prev))
+		prev = w >> 63
+	}
+	return n
+}
+
+// and 原地和 other 求交集
+func (b *bitmapContainer) and(other container) {
+	if o, ok := other.(*bitmapContainer); ok {
+		b.bits.And(&o.bits)
+	} else {
+		b.bits.And(&other.toBitmap().bits)
+	}
+	b.card = b.bits.Count()
+}
+
+// or 原地和 other 求并集
+func (b *bitmapContainer) or(other container) {
+	switch o := other.(type) {
+	case *bitmapContainer:
+		b.bits.Or(&o.bits)
+	case *runContainer:
+		for _, r := range o.runs {
+			b.bits.SetRange(uint(r.start), uint(r.last)+1)
+		}
+	default:
+		other.iterate(func(x uint16) bool {
+			b.bits.Set(uint(x))
+			return true
+		})
+	}
+	b.card = b.bits.Count()
+}
+
+// xor 原地和 other 求对称差
+func (b *bitmapContainer) xor(other container) {
+	switch o := other.(type) {
+	case *bitmapContainer:
+		b.bits.Xor(&o.bits)
+	case *runContainer:
+		for _, r := range o.runs {
+			b.bits.FlipRange(uint(r.start), uint(r.last)+1)
+		}
+	default:
+		other.iterate(func(x uint16) bool {
+			b.bits.Flip(uint(x))
+			return true
+		})
+	}
+	b.card = b.bits.Count()
+}
+
+// andNot 原地删除 other 中的元素
+func (b *bitmapContainer) andNot(other container) {
+	switch o := other.(type) {
+	case *bitmapContainer:
+		b.bits.AndNot(&o.bits)
+	case *runContainer:
+		for _, r := range o.runs {
+			b.bits.ClearRange(uint(r.start), uint(r.last)+1)
+		}
+	default:
+		other.iterate(func(x uint16) bool {
+			b.bits.Delete(uint(x))
+			return true
+		})
+	}
+	b.card = b.bits.Count()
+}
+
+// runInterval 是闭区间 [start, last]，用 last 而不是长度是为了能表示 65536 个元素
+type runInterval struct {
+	start uint16
+	last  uint16
+}
+
+func (r runInterval) size() int {
+	return int(r.last) - int(r.start) + 1
+}
+
+// runContainer 是有序且互不相邻的区间，适合元素连成片的场景
+type runContainer struct {
+	runs []runInterval
+}
+
+// newRunContainer 把任意容器转换为 runContainer
+func newRunContainer(c container) *runContainer {
+	runs := make([]runInterval, 0, c.numRuns())
+	c.iterate(func(x uint16) bool {
+		if n := len(runs); n > 0 && int(runs[n-1].last)+1 == int(x) {
+			runs[n-1].last = x
+		} else {
+			runs = append(runs, runInterval{start: x, last: x})
+		}
+		return true
+	})
+	return &runContainer{runs: runs}
+}
+
+// search 返回第一个 start 大于 x 的区间的下标，x 只可能在它前面的那个区间中
+func (r *runContainer) search(x uint16) int {
+	return sort.Search(len(r.runs), func(i int) bool {
+		return r.runs[i].start > x
+	})
+}
+
+func (r *runContainer) contains(x uint16) bool {
+	i := r.search(x)
+	return i > 0 && r.runs[i-1].last >= x
+}
+
+func (r *runContainer) add(x uint16) container {
+	i := r.search(x)
+	if i > 0 && r.runs[i-1].last >= x {
+		return r
+	}
+	// 走到这里说明前一个区间的 last 小于 x，后一个区间的 start 大于 x，所以加一不会溢出
+	mergePrev := i > 0 && r.runs[i-1].last+1 == x
+	mergeNext := i < len(r.runs) && r.runs[i].start == x+1
+	switch {
+	case mergePrev && mergeNext:
+		r.runs[i-1].last = r.runs[i].last
+		r.runs = append(r.runs[:i], r.runs[i+1:]...)
+	case mergePrev:
+		r.runs[i-1].last = x
+	case mergeNext:
+		r.runs[i].start = x
+	default:
+		r.runs = append(r.runs, runInterval{})
+		copy(r.runs[i+1:], r.runs[i:])
+		r.runs[i] = runInterval{start: x, last: x}
+	}
+	return r
+}
+
+func (r *runContainer) remove(x uint16) container {
+	i := r.search(x)
+	if i == 0 || r.runs[i-1].last < x {
+		return r
+	}
+	i--
+	run := r.runs[i]
+	switch {
+	case run.start == run.last:
+		r.runs = append(r.runs[:i], r.runs[i+1:]...)
+	case x == run.start:
+		r.runs[i].start++
+	case x == run.last:
+		r.runs[i].last--
+	default:
+		r.runs[i].last = x - 1
+		r.runs = append(r.runs, runInterval{})
+		copy(r.runs[i+2:], r.runs[i+1:])
+		r.runs[i+1] = runInterval{start: x + 1, last: run.last}
+	}
+	return r
+}
+
+func (r *runContainer) cardinality() int {
+	n := 0
+	for _, run := range r.runs {
+		n += run.size()
+	}
+	return n
+}
+
+func (r *runContainer) rank(x uint16) int {
+	n := 0
+	for _, run := range r.runs {
+		if run.start >= x {
+			break
+		}
+		if run.last < x {
+			n += run.size()
+		} else {
+			n += int(x) - int(run.start)
+			break
+		}
+	}
+	return n
+}
+
+func (r *runContainer) selectAt(i int) uint16 {
+	for _, run := range r.runs {
+		if size := run.size(); i >= size {
+			i -= size
+			continue
+		}
+		return run.start + uint16(i)
+	}
+	return 0
+}
+
+func (r *runContainer) iterate(fn func(x uint16) bool) bool {
+	for _, run := range r.runs {
+		for x := int(run.start); x <= int(run.last); x++ {
+			if !fn(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *runContainer) clone() container {
+	return &runContainer{runs: append([]runInterval(nil), r.runs...)}
+}
+
+func (r *runContainer) toBitmap() *bitmapContainer {
+	b := newBitmapContainer()
+	for _, run := range r.runs {
+		b.bits.SetRange(uint(run.start), uint(run.last)+1)
+	}
+	b.card = r.cardinality()
+	return b
+}
+
+func (r *runContainer) numRuns() int {
+	return len(r.runs)
+}
+
+// and 求两组区间的交集
+func (r *runContainer) and(other *runContainer) *runContainer {
+	res := make([]runInterval, 0)
+	i, j := 0, 0
+	for i < len(r.runs) && j < len(other.runs) {
+		a, b := r.runs[i], other.runs[j]
+		start, last := a.start, a.last
+		if b.start > start {
+			start = b.start
+		}
+		if b.last < last {
+			last = b.last
+		}
+		if start <= last {
+			res = append(res, runInterval{start: start, last: last})
+		}
+		if a.last < b.last {
+			i++
+		} else {
+			j++
+		}
+	}
+	return &runContainer{runs: res}
+}
+
+// or 求两组区间的并集，相邻的区间会被合并
+func (r *runContainer) or(other *runContainer) *runContainer {
+	res := make([]runInterval, 0, len(r.runs)+len(other.runs))
+	i, j := 0, 0
+	for i < len(r.runs) || j < len(other.runs) {
+		var next runInterval
+		if j == len(other.runs) || (i < len(r.runs) && r.runs[i].start <= other.runs[j].start) {
+			next = r.runs[i]
+			i++
+		} else {
+			next = other.runs[j]
+			j++
+		}
+		if n := len(res); n > 0 && int(next.start) <= int(res[n-1].last)+1 {
+			if next.last > res[n-1].last {
+				res[n-1].last = next.last
+			}
+			continue
+		}
+		res = append(res, next)
+	}
+	return &runContainer{runs: res}
+}
+
+// toEfficient 按照序列化之后的大小选择最节省空间的容器，和 Roaring 的参考实现保持一致：
+// 区间不比另外两种大的时候使用 runContainer，否则按照元素数量选择 arrayContainer 或者 bitmapContainer
+func toEfficient(c container) container {
+	card := c.cardinality()
+	runSize := runContainerSize(c.numRuns())
+	if runSize <= bitmapContainerSize && runSize <= arrayContainerSize(card) {
+		if _, ok := c.(*runContainer); ok {
+			return c
+		}
+		return newRunContainer(c)
+	}
+	if card <= arrayContainerMaxSize {
+		if _, ok := c.(*arrayContainer); ok {
+			return c
+		}
+		return toArrayContainer(c)
+	}
+	if _, ok := c.(*bitmapContainer); ok {
+		return c
+	}
+	return c.toBitmap()
+}
+
+func toArrayContainer(c container) *arrayContainer {
+	res := make([]uint16, 0, c.cardinality())
+	c.iterate(func(x uint16) bool {
+		res = append(res, x)
+		return true
+	})
+	return &arrayContainer{content: res}
+}
+
+// shrink 处理位图运算的结果
+// 参与运算的有 runContainer 的时候尽量保留区间的形式，否则元素少的时候退化为 arrayContainer
+func shrink(b *bitmapContainer, hasRun bool) container {
+	if hasRun {
+		return toEfficient(b)
+	}
+	if b.card <= arrayContainerMaxSize {
+		return b.toArray()
+	}
+	return b
+}
+
+func isRunContainer(c container) bool {
+	_, ok := c.(*runContainer)
+	return ok
+}
+
+// 下面的运算都不会修改参与运算的容器，结果总是新的容器
+
+func containerAnd(a, b container) container {
+	if x, ok := a.(*arrayContainer); ok {
+		return x.filter(b, true)
+	}
+	if y, ok := b.(*arrayContainer); ok {
+		return y.filter(a, true)
+	}
+	x, okx := a.(*runContainer)
+	y, oky := b.(*runContainer)
+	if okx && oky {
+		return toEfficient(x.and(y))
+	}
+	res := a.toBitmap()
+	res.and(b)
+	return shrink(res, okx || oky)
+}
+
+func containerOr(a, b container) container {
+	if x, ok := a.(*arrayContainer); ok {
+		if y, ok := b.(*arrayContainer); ok {
+			return x.merge(y, func(inA, inB bool) bool {
+				return true
+			})
+		}
+	}
+	x, okx := a.(*runContainer)
+	y, oky := b.(*runContainer)
+	if okx && oky {
+		return toEfficient(x.or(y))
+	}
+	res := a.toBitmap()
+	res.or(b)
+	return shrink(res, okx || oky)
+}
+
+func containerXor(a, b container) container {
+	if x, ok := a.(*arrayContainer); ok {
+		if y, ok := b.(*arrayContainer); ok {
+			return x.merge(y, func(inA, inB bool) bool {
+				return inA != inB
+			})
+		}
+	}
+	res := a.toBitmap()
+	res.xor(b)
+	return shrink(res, isRunContainer(a) || isRunContainer(b))
+}
+
+func containerAndNot(a, b container) container {
+	if x, ok := a.(*arrayContainer); ok {
+		return x.filter(b, false)
+	}
+	res := a.toBitmap()
+	res.andNot(b)
+	return shrink(res, isRunContainer(a) || isRunContainer(b))
+}
+
+func containerEqual(a, b container) bool {
+	if a.cardinality() != b.cardinality() {
+		return false
+	}
+	return a.iterate(b.contains)
+}
+
+// 下面是容器序列化之后的字节数
+
+const bitmapContainerSize = bitmapContainerWords * 8
+
+func arrayContainerSize(card int) int {
+	return 2 * card
+}
+
+func runContainerSize(numRuns int) int {
+	return 2 + 4*numRuns
+}
@@ -0,0 +1,84 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunContainer_AddRemove(t *testing.T) {
+	r := &runContainer{}
+	var c container = r
+	for _, x := range []uint16{5, 7, 6, 1, 65535, 0, 65534} {
+		c = c.add(x)
+	}
+	assert.Equal(t, []runInterval{{0, 1}, {5, 7}, {65534, 65535}}, r.runs)
+	assert.Equal(t, 7, c.cardinality())
+
+	c = c.remove(6)
+	assert.Equal(t, []runInterval{{0, 1}, {5, 5}, {7, 7}, {65534, 65535}}, r.runs)
+	c = c.remove(5)
+	c = c.remove(0)
+	c = c.remove(65535)
+	c = c.remove(100)
+	assert.Equal(t, []runInterval{{1, 1}, {7, 7}, {65534, 65534}}, r.runs)
+	assert.True(t, c.contains(7))
+	assert.False(t, c.contains(8))
+}
+
+func TestRunContainer_Ops(t *testing.T) {
+	a := &runContainer{runs: []runInterval{{0, 10}, {20, 30}, {65530, 65535}}}
+	b := &runContainer{runs: []runInterval{{5, 19}, {25, 25}, {31, 40}}}
+	assert.Equal(t, []runInterval{{5, 10}, {25, 25}}, a.and(b).runs)
+	assert.Equal(t, []runInterval{{0, 40}, {65530, 65535}}, a.or(b).runs)
+}
+
+func TestContainer_RankSelect(t *testing.T) {
+	values := []uint16{0, 1, 2, 63, 64, 1000, 65535}
+	arr := &arrayContainer{content: values}
+	containers := []container{arr, arr.toBitmap(), newRunContainer(arr)}
+	for _, c := range containers {
+		for i, x := range values {
+			assert.Equal(t, i, c.rank(x))
+			assert.Equal(t, x, c.selectAt(i))
+		}
+		assert.Equal(t, 3, c.rank(50))
+		assert.Equal(t, 4, c.numRuns())
+	}
+}
+
+func TestBitmapContainer_NumRuns(t *testing.T) {
+	b := newBitmapContainer()
+	// 跨越两个字的区间只算一个
+	b.bits.SetRange(60, 70)
+	b.bits.SetRange(128, 192)
+	b.bits.Set(65535)
+	assert.Equal(t, 3, b.numRuns())
+}
+
+func TestToEfficient(t *testing.T) {
+	full := newBitmapContainer()
+	full.bits.SetRange(0, 1<<16)
+	full.card = 1 << 16
+	assert.IsType(t, &runContainer{}, toEfficient(full))
+	assert.Equal(t, []runInterval{{0, 65535}}, toEfficient(full).(*runContainer).runs)
+
+	sparse := &arrayContainer{content: []uint16{1, 3, 5}}
+	assert.Same(t, sparse, toEfficient(sparse))
+
+	// 三个连续元素的区间和数组大小相同，这个时候使用区间
+	triple := &arrayContainer{content: []uint16{1, 2, 3}}
+	assert.IsType(t, &runContainer{}, toEfficient(triple))
+	pair := &arrayContainer{content: []uint16{1, 2}}
+	assert.Same(t, pair, toEfficient(pair))
+
+	dense := newBitmapContainer()
+	for i := uint(0); i < 10000; i += 2 {
+		dense.bits.Set(i)
+	}
+	dense.card = 5000
+	assert.Same(t, dense, toEfficient(dense))
+
+	runs := &runContainer{runs: []runInterval{{1, 1}, {3, 3}}}
+	assert.IsType(t, &arrayContainer{}, toEfficient(runs))
+}
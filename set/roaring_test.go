@@ -0,0 +1,352 @@
+package set
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Set[uint32] = (*Roaring)(nil)
+
+func TestRoaring_Basic(t *testing.T) {
+	r := NewRoaring()
+	r.AddAll(5, 1, 1<<20, 4294967295, 5)
+	assert.Equal(t, 4, r.Len())
+	assert.True(t, r.Exist(1<<20))
+	assert.False(t, r.Exist(1<<20+1))
+	assert.Equal(t, []uint32{1, 5, 1 << 20, 4294967295}, r.Keys())
+
+	r.Delete(1 << 20)
+	r.Delete(1 << 21)
+	assert.Equal(t, []uint32{1, 5, 4294967295}, r.Keys())
+	// 桶中的元素全部删除之后桶也会被删除
+	assert.Equal(t, []uint16{0, 0xFFFF}, r.keys)
+
+	var got []uint32
+	r.Range(func(key uint32) bool {
+		got = append(got, key)
+		return len(got) < 2
+	})
+	assert.Equal(t, []uint32{1, 5}, got)
+
+	r.Clear()
+	assert.Equal(t, 0, r.Len())
+	assert.Empty(t, r.Keys())
+}
+
+func TestRoaring_ContainerConversion(t *testing.T) {
+	var r Roaring
+	for i := uint32(0); i < arrayContainerMaxSize; i++ {
+		r.Add(2 * i)
+	}
+	assert.IsType(t, &arrayContainer{}, r.containers[0])
+	r.Add(1)
+	assert.IsType(t, &bitmapContainer{}, r.containers[0])
+	r.Delete(1)
+	assert.IsType(t, &arrayContainer{}, r.containers[0])
+
+	// 连续的元素在 RunOptimize 之后使用区间保存
+	r.Clear()
+	for i := uint32(0); i < 10000; i++ {
+		r.Add(i)
+	}
+	r.RunOptimize()
+	assert.IsType(t, &runContainer{}, r.containers[0])
+	r.Delete(5000)
+	assert.Equal(t, 9999, r.Len())
+	assert.False(t, r.Exist(5000))
+	r.Add(5000)
+	assert.Equal(t, []runInterval{{start: 0, last: 9999}}, r.containers[0].(*runContainer).runs)
+}
+
+func TestRoaring_RankSelect(t *testing.T) {
+	var r Roaring
+	r.AddAll(3, 10, 1<<16, 1<<16+5, 1<<30)
+	for i := uint32(100); i < 6000; i++ {
+		r.Add(1<<17 + i)
+	}
+	runs := r.Clone()
+	runs.RunOptimize()
+	for _, b := range []*Roaring{&r, runs} {
+		assert.Equal(t, 0, b.Rank(3))
+		assert.Equal(t, 1, b.Rank(4))
+		assert.Equal(t, 2, b.Rank(1<<16))
+		assert.Equal(t, 4, b.Rank(1<<17))
+		assert.Equal(t, 4+100, b.Rank(1<<17+200))
+		assert.Equal(t, b.Len()-1, b.Rank(1<<30))
+		assert.Equal(t, b.Len(), b.Rank(4294967295))
+
+		keys := b.Keys()
+		for i, want := range keys {
+			got, err := b.Select(i)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+			assert.Equal(t, i, b.Rank(want))
+		}
+		_, err := b.Select(len(keys))
+		assert.Error(t, err)
+		_, err = b.Select(-1)
+		assert.Error(t, err)
+	}
+}
+
+// randomRoaring 生成同时包含三种容器的位图，以及对应的 MapSet
+func randomRoaring(r *rand.Rand) (*Roaring, *MapSet[uint32]) {
+	rb := NewRoaring()
+	m := NewMapSet[uint32](0)
+	add := func(key uint32) {
+		rb.Add(key)
+		m.Add(key)
+	}
+	for hi := uint32(0); hi < 6; hi++ {
+		base := hi << 16
+		switch r.Intn(3) {
+		case 0:
+			for i := 0; i < 100; i++ {
+				add(base + uint32(r.Intn(1<<16)))
+			}
+		case 1:
+			for i := 0; i < 6000; i++ {
+				add(base + uint32(r.Intn(1<<16)))
+			}
+		case 2:
+			for i := 0; i < 3; i++ {
+				start := uint32(r.Intn(60000))
+				for j := uint32(0); j < 2000; j++ {
+					add(base + start + j)
+				}
+			}
+		}
+	}
+	if r.Intn(2) == 0 {
+		rb.RunOptimize()
+	}
+	return rb, m
+}
+
+func sortedKeys(m *MapSet[uint32]) []uint32 {
+	keys := m.Keys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func TestRoaring_Bulk(t *testing.T) {
+	testCases := []struct {
+		name string
+		op   func(a, b *Roaring)
+		keep func(inA, inB bool) bool
+	}{
+		{name: "and", op: (*Roaring).And, keep: func(inA, inB bool) bool { return inA && inB }},
+		{name: "or", op: (*Roaring).Or, keep: func(inA, inB bool) bool { return inA || inB }},
+		{name: "xor", op: (*Roaring).Xor, keep: func(inA, inB bool) bool { return inA != inB }},
+		{name: "and not", op: (*Roaring).AndNot, keep: func(inA, inB bool) bool { return inA && !inB }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 20; i++ {
+				a, ma := randomRoaring(r)
+				b, mb := randomRoaring(r)
+				bKeys := b.Keys()
+
+				want := NewMapSet[uint32](0)
+				for _, key := range append(ma.Keys(), mb.Keys()...) {
+					if tc.keep(ma.Exist(key), mb.Exist(key)) {
+						want.Add(key)
+					}
+				}
+				tc.op(a, b)
+				assert.Equal(t, sortedKeys(want), a.Keys())
+				assert.Equal(t, want.Len(), a.Len())
+				// 参与运算的另一个位图不会被修改
+				assert.Equal(t, bKeys, b.Keys())
+			}
+		})
+	}
+}
+
+func TestRoaring_BulkSelf(t *testing.T) {
+	r, m := randomRoaring(rand.New(rand.NewSource(2)))
+	want := sortedKeys(m)
+	r.Or(r)
+	assert.Equal(t, want, r.Keys())
+	r.And(r)
+	assert.Equal(t, want, r.Keys())
+	r.Xor(r)
+	assert.Equal(t, 0, r.Len())
+}
+
+func TestRoaring_Equal(t *testing.T) {
+	a := NewRoaring()
+	b := NewRoaring()
+	for i := uint32(0); i < 5000; i++ {
+		a.Add(i)
+		b.Add(i)
+	}
+	// 容器的类型不影响结果
+	b.RunOptimize()
+	assert.True(t, a.Equal(b))
+	b.Delete(10)
+	assert.False(t, a.Equal(b))
+	b.Add(1 << 20)
+	assert.False(t, a.Equal(b))
+
+	m := NewMapSet[uint32](3)
+	m.AddAll(1, 2, 1<<20)
+	c := NewRoaring()
+	c.AddAll(1, 2, 1<<20)
+	assert.True(t, c.Equal(m))
+	m.Delete(2)
+	assert.False(t, c.Equal(m))
+}
+
+// 下面的位图都使用 Roaring 的参考实现序列化，bitmapwith*.bin 来自 Roaring 格式规范中的测试数据
+func TestRoaring_Golden(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		optimize bool
+		build    func(r *Roaring)
+	}{
+		{
+			name:  "empty",
+			file:  "empty.bin",
+			build: func(r *Roaring) {},
+		},
+		{
+			name: "sparse",
+			file: "sparse.bin",
+			build: func(r *Roaring) {
+				r.AddAll(0, 1, 65535, 65536, 1<<31, 4294967295)
+			},
+		},
+		{
+			name:     "few runs without offsets",
+			file:     "fewruns.bin",
+			optimize: true,
+			build: func(r *Roaring) {
+				for k := uint32(10); k < 1000; k++ {
+					r.Add(k)
+				}
+				r.Add(70000)
+			},
+		},
+		{
+			name:     "mixed",
+			file:     "mixed.bin",
+			optimize: true,
+			build: func(r *Roaring) {
+				for k := uint32(0); k < 65536; k++ {
+					r.Add(k)
+				}
+				for k := uint32(200000); k < 300000; k++ {
+					r.Add(k)
+				}
+				for k := uint32(0); k < 5000; k++ {
+					r.Add(1<<20 + 3*k)
+				}
+				for k := uint32(0); k < 100; k++ {
+					r.Add(1<<30 + 7*k)
+				}
+			},
+		},
+		{
+			name:  "spec without runs",
+			file:  "bitmapwithoutruns.bin",
+			build: buildSpecBitmap,
+		},
+		{
+			name:     "spec with runs",
+			file:     "bitmapwithruns.bin",
+			optimize: true,
+			build:    buildSpecBitmap,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			golden, err := os.ReadFile(filepath.Join("testdata", "roaring", tc.file))
+			require.NoError(t, err)
+			want := NewRoaring()
+			tc.build(want)
+			if tc.optimize {
+				want.RunOptimize()
+			}
+
+			data, err := want.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, golden, data)
+
+			got := NewRoaring()
+			require.NoError(t, got.UnmarshalBinary(golden))
+			assert.Equal(t, want.Keys(), got.Keys())
+			data, err = got.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, golden, data)
+		})
+	}
+}
+
+// buildSpecBitmap 生成 Roaring 格式规范中测试数据的内容
+func buildSpecBitmap(r *Roaring) {
+	for k := uint32(0); k < 100000; k += 1000 {
+		r.Add(k)
+	}
+	for k := uint32(100000); k < 200000; k++ {
+		r.Add(3 * k)
+	}
+	for k := uint32(700000); k < 800000; k++ {
+		r.Add(k)
+	}
+}
+
+func TestRoaring_UnmarshalInvalid(t *testing.T) {
+	// crash*.bin 来自 Roaring 参考实现的测试数据，都是不合法的输入
+	files, err := filepath.Glob(filepath.Join("testdata", "roaring", "crash*.bin"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		r := NewRoaring()
+		r.Add(1)
+		assert.Equal(t, errRoaringInvalidData, r.UnmarshalBinary(data), file)
+		// 失败的时候不会修改原本的元素
+		assert.Equal(t, []uint32{1}, r.Keys())
+	}
+
+	valid := NewRoaring()
+	valid.AddAll(1, 2, 3)
+	data, err := valid.MarshalBinary()
+	require.NoError(t, err)
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated", data: data[:len(data)-1]},
+		{name: "trailing", data: append(append([]byte(nil), data...), 0)},
+		// 元素不是严格递增的
+		{name: "unsorted", data: append(append([]byte(nil), data[:len(data)-2]...), 1, 0)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, errRoaringInvalidData, NewRoaring().UnmarshalBinary(tc.data))
+		})
+	}
+}
+
+func TestRoaring_MarshalRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 10; i++ {
+		want, _ := randomRoaring(r)
+		data, err := want.MarshalBinary()
+		require.NoError(t, err)
+		got := NewRoaring()
+		require.NoError(t, got.UnmarshalBinary(data))
+		assert.True(t, want.Equal(got))
+	}
+}
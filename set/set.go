@@ -1,11 +1,21 @@
 package set
 
-type Set[T comparable] interface {
+type Set[T any] interface {
 	Add(key T)
 	Delete(key T)
 	// Exist 返回是否存在这个元素
 	Exist(key T) bool
 	Keys() []T
+	// Len 返回元素的数量
+	Len() int
+	// Range 遍历所有的元素，fn 返回 false 的时候中断遍历
+	Range(fn func(key T) bool)
+	// Clear 删除所有的元素
+	Clear()
+	// AddAll 添加多个元素
+	AddAll(keys ...T)
+	// Equal 判断两个集合中的元素是否完全相同
+	Equal(other Set[T]) bool
 }
 
 type MapSet[T comparable] struct {
@@ -39,3 +49,47 @@ func (s *MapSet[T]) Keys() []T {
 	}
 	return ans
 }
+
+func (s *MapSet[T]) Len() int {
+	return len(s.m)
+}
+
+// Range 遍历的顺序不固定
+func (s *MapSet[T]) Range(fn func(key T) bool) {
+	for key := range s.m {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (s *MapSet[T]) Clear() {
+	s.m = make(map[T]struct{})
+}
+
+func (s *MapSet[T]) AddAll(keys ...T) {
+	for _, key := range keys {
+		s.m[key] = struct{}{}
+	}
+}
+
+func (s *MapSet[T]) Equal(other Set[T]) bool {
+	return equalSet[T](s, other)
+}
+
+func (s *MapSet[T]) newEmpty() Set[T] {
+	return NewMapSet[T](0)
+}
+
+// equalSet 判断两个集合是否相等，元素数量相同的情况下，只需要判断 a 中的元素都在 b 中
+func equalSet[T any](a, b Set[T]) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	res := true
+	a.Range(func(key T) bool {
+		res = b.Exist(key)
+		return res
+	})
+	return res
+}
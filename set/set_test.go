@@ -119,6 +119,40 @@ func TestSetx_Values(t *testing.T) {
 	}
 }
 
+func TestMapSet_Bulk(t *testing.T) {
+	s := NewMapSet[int](10)
+	s.AddAll(1, 2, 3, 2)
+	assert.Equal(t, 3, s.Len())
+
+	var keys []int
+	s.Range(func(key int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.ElementsMatch(t, []int{1, 2, 3}, keys)
+	cnt := 0
+	s.Range(func(key int) bool {
+		cnt++
+		return false
+	})
+	assert.Equal(t, 1, cnt)
+
+	other := NewMapSet[int](3)
+	other.AddAll(3, 2)
+	assert.False(t, s.Equal(other))
+	other.Add(1)
+	assert.True(t, s.Equal(other))
+	other.Delete(1)
+	other.Add(4)
+	assert.False(t, s.Equal(other))
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+	assert.False(t, s.Exist(1))
+	s.Add(1)
+	assert.Equal(t, []int{1}, s.Keys())
+}
+
 func equal(nums []int, m map[int]struct{}) bool {
 	for _, num := range nums {
 		_, ok := m[num]
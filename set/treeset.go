@@ -6,6 +6,7 @@ import (
 )
 
 type TreeSet[T any] struct {
+	compare xkit.Comparator[T]
 	treeMap *mapx.TreeMap[T, any]
 }
 
@@ -15,6 +16,7 @@ func NewTreeSet[T any](compare xkit.Comparator[T]) (*TreeSet[T], error) {
 		return nil, err
 	}
 	return &TreeSet[T]{
+		compare: compare,
 		treeMap: treeMap,
 	}, nil
 }
@@ -36,3 +38,65 @@ func (s *TreeSet[T]) Exist(key T) bool {
 func (s *TreeSet[T]) Keys() []T {
 	return s.treeMap.Keys()
 }
+
+func (s *TreeSet[T]) Len() int {
+	return int(s.treeMap.Len())
+}
+
+// Range 从小到大遍历所有的元素
+func (s *TreeSet[T]) Range(fn func(key T) bool) {
+	for _, key := range s.treeMap.Keys() {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (s *TreeSet[T]) Clear() {
+	s.treeMap, _ = mapx.NewTreeMap[T, any](s.compare)
+}
+
+func (s *TreeSet[T]) AddAll(keys ...T) {
+	for _, key := range keys {
+		_ = s.treeMap.Put(key, nil)
+	}
+}
+
+// Equal 判断两个集合中的元素是否完全相同
+// 如果 other 也是 TreeSet，那么按顺序逐个比较，复杂度是 O(n)
+func (s *TreeSet[T]) Equal(other Set[T]) bool {
+	if o, ok := other.(*TreeSet[T]); ok {
+		if s.Len() != o.Len() {
+			return false
+		}
+		res := true
+		mergeSorted(s.treeMap.Keys(), o.treeMap.Keys(), s.compare, func(key T, inA, inB bool) bool {
+			res = inA && inB
+			return res
+		})
+		return res
+	}
+	return equalSet[T](s, other)
+}
+
+func (s *TreeSet[T]) newEmpty() Set[T] {
+	res, _ := NewTreeSet[T](s.compare)
+	return res
+}
+
+// Rank 返回小于 key 的元素数量，key 本身不需要存在
+func (s *TreeSet[T]) Rank(key T) int {
+	return s.treeMap.Rank(key)
+}
+
+// Select 返回从小到大排序之后下标为 index 的元素，下标从 0 开始
+// 下标超出范围会返回错误
+func (s *TreeSet[T]) Select(index int) (T, error) {
+	key, _, err := s.treeMap.Select(index)
+	return key, err
+}
+
+// CountRange 返回落在 [lo, hi) 中的元素数量
+func (s *TreeSet[T]) CountRange(lo, hi T) int {
+	return s.treeMap.CountRange(lo, hi)
+}
@@ -139,6 +139,57 @@ func TestTreeSet_Exist(t *testing.T) {
 	}
 }
 
+func TestTreeSet_RankSelect(t *testing.T) {
+	treeSet, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	// 模拟延迟样本，求 P50 和 P90
+	for i := 100; i > 0; i-- {
+		treeSet.Add(i)
+	}
+	p50, err := treeSet.Select(49)
+	require.NoError(t, err)
+	assert.Equal(t, 50, p50)
+	p90, err := treeSet.Select(89)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p90)
+	_, err = treeSet.Select(100)
+	assert.Error(t, err)
+	assert.Equal(t, 9, treeSet.Rank(10))
+	assert.Equal(t, 10, treeSet.CountRange(11, 21))
+}
+
+func TestTreeSet_Bulk(t *testing.T) {
+	s, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	s.AddAll(3, 1, 2, 1)
+	assert.Equal(t, 3, s.Len())
+
+	var keys []int
+	s.Range(func(key int) bool {
+		keys = append(keys, key)
+		return key < 2
+	})
+	assert.Equal(t, []int{1, 2}, keys)
+
+	other, err := NewTreeSet[int](compare())
+	require.NoError(t, err)
+	other.AddAll(1, 2, 4)
+	assert.False(t, s.Equal(other))
+	other.Delete(4)
+	assert.False(t, s.Equal(other))
+	other.Add(3)
+	assert.True(t, s.Equal(other))
+	mapSet := NewMapSet[int](3)
+	mapSet.AddAll(1, 2, 3)
+	assert.True(t, s.Equal(mapSet))
+	assert.True(t, mapSet.Equal(s))
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+	s.Add(5)
+	assert.Equal(t, []int{5}, s.Keys())
+}
+
 func compare() xkit.Comparator[int] {
 	return xkit.ComparatorRealNumber[int]
 }
@@ -54,3 +54,18 @@ func (rb *RBTree[K, V]) Size() int {
 func (rb *RBTree[K, V]) KeyValues() ([]K, []V) {
 	return rb.rbTree.KeyValues()
 }
+
+// Rank 返回小于 key 的节点数量，key 本身不需要存在
+func (rb *RBTree[K, V]) Rank(key K) int {
+	return rb.rbTree.Rank(key)
+}
+
+// Select 返回按照从小到大排序之后下标为 index 的节点，下标从 0 开始
+func (rb *RBTree[K, V]) Select(index int) (K, V, error) {
+	return rb.rbTree.Select(index)
+}
+
+// CountRange 返回落在 [lo, hi) 中的节点数量
+func (rb *RBTree[K, V]) CountRange(lo, hi K) int {
+	return rb.rbTree.CountRange(lo, hi)
+}
@@ -171,3 +171,15 @@ func TestRBTree_KeyValues(t *testing.T) {
 		})
 	}
 }
+
+func TestRBTree_RankSelect(t *testing.T) {
+	rbTree, _ := NewRBTree[int, string](compare())
+	for _, k := range []int{3, 1, 2} {
+		assert.NoError(t, rbTree.Add(k, ""))
+	}
+	assert.Equal(t, 1, rbTree.Rank(2))
+	k, _, err := rbTree.Select(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, k)
+	assert.Equal(t, 2, rbTree.CountRange(1, 3))
+}